@@ -1,8 +1,8 @@
 package arbitrage
 
 import (
+	"context"
 	"fmt"
-	"math"
 	"sync"
 	"time"
 
@@ -10,233 +10,423 @@ import (
 
 	"goarbitrage/config"
 	"goarbitrage/exchanges"
+	"goarbitrage/paper"
+	"goarbitrage/recorder"
+)
+
+const (
+	REFRESH_RATE  = 5 * time.Second
+	DEPTH_TIMEOUT = 5 * time.Second
+	MAX_BOOK_AGE  = 30 * time.Second
 )
 
 type (
 	ArbitrageStrategy struct {
 		Exchanges map[string]exchange.IBotExchange
-		Depths    map[string]exchange.OrderBook
-		shutdown  chan struct{}
+		// Depths holds the last books by canonical pair then exchange name
+		Depths   map[string]map[string]exchange.OrderBook
+		Balances map[string]map[string]exchange.Balance
+		Paper    *paper.Trader
+		Recorder *recorder.Recorder
+		Strategy Strategy
+		// RefreshRate is the pause between two ticks of Loop, DepthTimeout
+		// bounds the wait for the books of a single update
+		RefreshRate  time.Duration
+		DepthTimeout time.Duration
+		// MaxBookAge evicts older books before every tick, MaxBookSkew skips
+		// routes whose books were received further apart
+		MaxBookAge  time.Duration
+		MaxBookSkew time.Duration
+		// polled holds when the books of every exchange were last polled
+		polled map[string]time.Time
 	}
 
 	ProfitStruct struct {
-		Profit            float64
+		GrossProfit       float64
+		BuyFee            float64
+		SellFee           float64
+		NetProfit         float64
 		Volume            float64
 		WeightedBuyPrice  float64
 		WeightedSellPrice float64
 		BuyPrice          float64
 		SellPrice         float64
+		Rejected          string
+	}
+
+	// Decision is a route a strategy wants to trade, Spread is the net
+	// profit in percent of the bought volume
+	Decision struct {
+		Pair   exchange.CurrencyPair
+		Buy    string
+		Sell   string
+		Spread float64
+		ProfitStruct
 	}
 )
 
-func New() *ArbitrageStrategy {
-	return &ArbitrageStrategy{
-		Depths: map[string]exchange.OrderBook{},
+// New creates the arbitrage loop driving the strategy registered under the
+// name, the spread strategy is used when the name is empty
+func New(strategy string) (*ArbitrageStrategy, error) {
+	if strategy == "" {
+		strategy = SPREAD_STRATEGY
 	}
-}
 
-func (a *ArbitrageStrategy) updateDepths() {
-	wg := sync.WaitGroup{}
-	done := make(chan struct{})
-	resp := make(chan exchange.TaskResponse, len(a.Exchanges))
+	s, err := NewStrategy(strategy)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, v := range a.Exchanges {
-		wg.Add(1)
-		go v.UpdateDepth(&wg, done, resp)
+	a := &ArbitrageStrategy{
+		Depths:       map[string]map[string]exchange.OrderBook{},
+		Balances:     map[string]map[string]exchange.Balance{},
+		Strategy:     s,
+		RefreshRate:  REFRESH_RATE,
+		DepthTimeout: DEPTH_TIMEOUT,
+		MaxBookAge:   MAX_BOOK_AGE,
+		polled:       map[string]time.Time{},
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+	settings := config.Cfg.Settings
+	if settings.RefreshRate.Duration > 0 {
+		a.RefreshRate = settings.RefreshRate.Duration
+	}
+	if settings.DepthTimeout.Duration > 0 {
+		a.DepthTimeout = settings.DepthTimeout.Duration
+	}
+	if settings.MaxBookAge.Duration > 0 {
+		a.MaxBookAge = settings.MaxBookAge.Duration
+	}
 
-		cnt := 0
-		timeout := time.After(5 * time.Second)
+	// books of a single update are never further apart than its window,
+	// books of exchanges polled less often are kept for their delay and up
+	// to one more round
+	a.MaxBookSkew = a.DepthTimeout
+	if delay := config.Cfg.MaxPollingDelay(); delay > 0 {
+		a.MaxBookSkew += delay + a.RefreshRate
+	}
+	if settings.MaxBookSkew.Duration > 0 {
+		a.MaxBookSkew = settings.MaxBookSkew.Duration
+	}
 
-		for {
-			select {
-			case <-timeout:
-				close(done)
-				return
-			case data := <-resp:
-				cnt++
-				log.Info("name:", "info", data.Name)
-				a.Depths[data.Name] = data.OrderBook
+	return a, nil
+}
+
+type depthResult struct {
+	name     string
+	pair     exchange.CurrencyPair
+	book     exchange.OrderBook
+	received time.Time
+	err      error
+}
+
+// updateDepths fetches the book of every enabled pair concurrently, except
+// on exchanges polled less than their polling delay ago which keep their
+// books. Each exchange works under its own deadline within the window of
+// DepthTimeout, requests still in flight when it expires or the parent
+// context ends are cancelled. Books that failed are dropped so that
+// strategies never trade on them, the errors are returned as
+// *exchange.DepthError.
+func (a *ArbitrageStrategy) updateDepths(parent context.Context) []error {
+	ctx, cancel := context.WithTimeout(parent, a.DepthTimeout)
+	defer cancel()
+
+	now := time.Now()
+	polling := map[string]exchange.IBotExchange{}
+	total := 0
+	for name, v := range a.Exchanges {
+		if delay := v.GetPollingDelay(); delay > 0 && now.Sub(a.polled[name]) < delay {
+			continue
+		}
+
+		a.polled[name] = now
+		polling[name] = v
+		total += len(v.GetEnabledPairs())
+	}
+	results := make(chan depthResult, total)
 
-				if cnt == len(a.Exchanges) {
-					return
+	wg := sync.WaitGroup{}
+	for name, v := range polling {
+		for _, pair := range v.GetEnabledPairs() {
+			wg.Add(1)
+			go func(name string, e exchange.IBotExchange, pair exchange.CurrencyPair) {
+				defer wg.Done()
+
+				ctx := ctx
+				if timeout := e.GetDepthTimeout(); timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, timeout)
+					defer cancel()
 				}
-			}
+
+				book, err := e.UpdateDepth(ctx, pair)
+				results <- depthResult{name: name, pair: pair, book: book, received: time.Now(), err: err}
+			}(name, v, pair)
 		}
-	}()
+	}
 
 	wg.Wait()
-}
-
-func (a *ArbitrageStrategy) tick() {
-	for k1, _ := range a.Depths {
-		for k2, _ := range a.Depths {
-			if k1 == k2 {
-				continue
-			}
+	close(results)
+
+	var errs []error
+	for r := range results {
+		if r.err != nil {
+			err := exchange.NewDepthError(r.name, r.pair, r.err)
+			log.Error("Error get order book", "error", err.Error(), "timeout", err.Timeout())
+			a.dropDepth(r.name, r.pair.String())
+			errs = append(errs, err)
+			continue
+		}
 
-			ex1 := a.Depths[k1]
-			ex2 := a.Depths[k2]
+		log.Info("name:", "info", r.name, "symbol", r.pair.String())
+		a.setDepth(r.name, r.pair.String(), r.book, r.received)
 
-			if ex1.Asks[0].Price < ex2.Bids[0].Price {
-				a.arbitrageOpportunity(k1, k2)
+		if a.Recorder != nil {
+			data := exchange.TaskResponse{Name: r.name, Symbol: r.pair.String(), OrderBook: r.book}
+			if err := a.Recorder.Record(data, r.received); err != nil {
+				log.Error("Error record order book", "error", err.Error())
 			}
 		}
 	}
+
+	return errs
 }
 
-func (a *ArbitrageStrategy) arbitrageOpportunity(kask, kbid string) {
-	r := a.arbitrageDepthOpportunity(kask, kbid)
-	if r.Volume == 0 || r.BuyPrice == 0 {
-		return
+// setDepth stores the book of the exchange under the canonical pair so that
+// the same pair of every exchange ends up in the same group, stamped with
+// the receive and exchange times
+func (a *ArbitrageStrategy) setDepth(name, symbol string, book exchange.OrderBook, received time.Time) {
+	group, rate := depthGroup(symbol)
+	if rate != 1 {
+		book = convertBook(book, rate)
 	}
 
-	perc := (r.WeightedSellPrice - r.WeightedBuyPrice) / r.BuyPrice * 100
-	log.Info("Percent:", "info", perc)
+	book.Received = received
+	book.Timestamp = book.LastUpdate()
 
-	s := config.Cfg.Settings
-	if r.Profit > s.ProfitThresh && perc > s.PercThresh {
-		log.Info(
-			fmt.Sprintf(
-				"profit: %f CNY with volume: %f BTC - buy at %.4f (%s) sell at %.4f (%s) ~%.2f%%",
-				r.Profit, r.Volume, r.BuyPrice, kask, r.SellPrice, kbid, perc,
-			), "info",
-		)
+	if a.Depths[group] == nil {
+		a.Depths[group] = map[string]exchange.OrderBook{}
 	}
 
-	return
+	a.Depths[group][name] = book
 }
 
-func (a *ArbitrageStrategy) arbitrageDepthOpportunity(kask, kbid string) ProfitStruct {
-	var (
-		profit                 ProfitStruct
-		bestAskPos, bestBidPos int
-	)
+// dropDepth removes the book of the exchange from its group
+func (a *ArbitrageStrategy) dropDepth(name, symbol string) {
+	group, _ := depthGroup(symbol)
+	delete(a.Depths[group], name)
+}
 
-	askPos, bidPos := a.getMaxDepth(kask, kbid)
-	for i := 0; i < askPos+1; i++ {
-		for j := 0; j < bidPos+1; j++ {
-			tempProfit := a.getProfitFor(i, j, kask, kbid)
+// depthGroup returns the pair books of the symbol are grouped under and the
+// rate repricing them. Books quoted in an asset with a configured conversion
+// are grouped with the target quote, without one a BTC/USDT book is never
+// compared to BTC/USD.
+func depthGroup(symbol string) (string, float64) {
+	pair, err := exchange.ParseCurrencyPair(symbol)
+	if err != nil {
+		return symbol, 1
+	}
 
-			if tempProfit.Profit > 0 && tempProfit.Profit >= profit.Profit {
-				profit = tempProfit
-				bestAskPos, bestBidPos = i, j
-			}
-		}
+	if conversion, ok := config.Cfg.Settings.QuoteConversions[pair.Quote]; ok && conversion.Rate > 0 {
+		return exchange.NewCurrencyPair(pair.Base, conversion.To).String(), conversion.Rate
 	}
 
-	profit.BuyPrice = a.Depths[kask].Asks[bestAskPos].Price
-	profit.SellPrice = a.Depths[kbid].Bids[bestBidPos].Price
-	return profit
+	return symbol, 1
 }
 
-func (a *ArbitrageStrategy) getMaxDepth(kask, kbid string) (int, int) {
-	var (
-		askPos, bidPos int
-	)
+func convertBook(book exchange.OrderBook, rate float64) exchange.OrderBook {
+	converted := exchange.OrderBook{
+		Bids: make([]exchange.ItemBook, len(book.Bids)),
+		Asks: make([]exchange.ItemBook, len(book.Asks)),
+	}
 
-	if len(a.Depths[kbid].Bids) > 0 && len(a.Depths[kask].Asks) > 0 {
-		for a.Depths[kask].Asks[askPos].Price < a.Depths[kbid].Bids[0].Price {
-			if askPos >= len(a.Depths[kask].Asks)-1 {
-				break
-			}
+	for i, item := range book.Bids {
+		item.Price *= rate
+		converted.Bids[i] = item
+	}
+	for i, item := range book.Asks {
+		item.Price *= rate
+		converted.Asks[i] = item
+	}
 
-			askPos += 1
+	return converted
+}
+
+// updateBalances refreshes the funds of every exchange with authenticated
+// API support, balances of failed exchanges are dropped so that they don't
+// cap opportunities with stale values
+func (a *ArbitrageStrategy) updateBalances(ctx context.Context) {
+	for name, v := range a.Exchanges {
+		if !v.IsAuthenticated() {
+			continue
 		}
 
-		for a.Depths[kask].Asks[0].Price < a.Depths[kbid].Bids[bidPos].Price {
-			if bidPos >= len(a.Depths[kbid].Bids)-1 {
-				break
-			}
+		balances, err := v.GetBalances(ctx)
+		if err != nil {
+			log.Error(fmt.Sprintf("Error get balances %s", name), "error", err.Error())
+			delete(a.Balances, name)
+			continue
+		}
 
-			bidPos += 1
+		a.Balances[name] = balances
+	}
+}
+
+// tick evicts the books that are stale at now, hands the rest and the
+// errors of their update to the strategy and executes its decisions. Routes
+// trading on books received too far apart are skipped.
+func (a *ArbitrageStrategy) tick(now time.Time, errs []error) []Decision {
+	a.evictStale(now)
+
+	decisions := a.Strategy.Evaluate(Snapshot{
+		Books:     a.Depths,
+		Errors:    errs,
+		Exchanges: a.Exchanges,
+		Funds:     a.funds,
+	})
+
+	var executed []Decision
+	for _, d := range decisions {
+		if reason := a.skewed(d); reason != "" {
+			log.Warn("Route skipped:", "pair", d.Pair.String(), "route", d.Buy+"->"+d.Sell, "reason", reason)
+			continue
 		}
+
+		a.execute(d)
+		executed = append(executed, d)
 	}
 
-	return askPos, bidPos
+	return executed
 }
 
-func (a *ArbitrageStrategy) getProfitFor(askPos, bidPos int, kask, kbid string) ProfitStruct {
-	if a.Depths[kask].Asks[askPos].Price >= a.Depths[kbid].Bids[bidPos].Price {
-		return ProfitStruct{}
+// evictStale drops the books received more than MaxBookAge before now. The
+// exchange time is only logged, quiet books keep old levels.
+func (a *ArbitrageStrategy) evictStale(now time.Time) {
+	for group, books := range a.Depths {
+		for name, book := range books {
+			if age := now.Sub(book.Received); age > a.MaxBookAge {
+				reason := fmt.Sprintf("received %s ago, max age %s", age.Round(time.Millisecond), a.MaxBookAge)
+				log.Warn("Book evicted:", "exchange", name, "pair", group, "reason", reason, "exchange_time", exchangeTime(book))
+				delete(books, name)
+			}
+		}
 	}
+}
 
-	var (
-		maxAmountBuy, maxAmountSell float64
-	)
+// skewed returns why the books of the decision can't be traded against each
+// other, empty when they were received close enough
+func (a *ArbitrageStrategy) skewed(d Decision) string {
+	books := a.Depths[d.Pair.String()]
+	skew := books[d.Buy].Received.Sub(books[d.Sell].Received)
+	if skew < 0 {
+		skew = -skew
+	}
 
-	for i := 0; i < askPos+1; i++ {
-		maxAmountBuy += a.Depths[kask].Asks[i].Amount
+	if skew > a.MaxBookSkew {
+		return fmt.Sprintf("books received %s apart, max skew %s, exchange times %s and %s",
+			skew.Round(time.Millisecond), a.MaxBookSkew, exchangeTime(books[d.Buy]), exchangeTime(books[d.Sell]))
 	}
 
-	for j := 0; j < bidPos+1; j++ {
-		maxAmountSell += a.Depths[kbid].Bids[j].Amount
+	return ""
+}
+
+// exchangeTime formats the exchange time of the book for the logs
+func exchangeTime(book exchange.OrderBook) string {
+	if book.Timestamp.IsZero() {
+		return "unknown"
 	}
 
-	//log.Info("Volume", "info", config.Cfg.Settings.MaxTxVolume)
-	maxAmount := math.Min(math.Min(maxAmountBuy, maxAmountSell), float64(1))
+	return book.Timestamp.UTC().Format(time.RFC3339Nano)
+}
 
-	var (
-		buyTotal, weightedBuyPrice float64
+func (a *ArbitrageStrategy) execute(d Decision) {
+	log.Info(
+		fmt.Sprintf(
+			"%s net profit: %f %s (gross: %f, fees: %f/%f) with volume: %f %s - buy at %.4f (%s) sell at %.4f (%s) ~%.2f%%",
+			d.Pair, d.NetProfit, d.Pair.Quote, d.GrossProfit, d.BuyFee, d.SellFee, d.Volume, d.Pair.Base, d.BuyPrice, d.Buy, d.SellPrice, d.Sell, d.Spread,
+		), "info",
 	)
-	for i := 0; i < askPos+1; i++ {
-		price := a.Depths[kask].Asks[i].Price
-		amount := math.Min(maxAmount, buyTotal+a.Depths[kask].Asks[i].Amount) - buyTotal
-		if amount <= .0 {
-			break
-		}
 
-		buyTotal += amount
-		if weightedBuyPrice == .0 {
-			weightedBuyPrice = price
-		} else {
-			weightedBuyPrice = (weightedBuyPrice*(buyTotal-amount) + price*amount) / buyTotal
-		}
+	if a.Paper != nil {
+		a.paperTrade(d.Pair, d.Buy, d.Sell, d.ProfitStruct)
+	}
+}
+
+func (a *ArbitrageStrategy) paperTrade(pair exchange.CurrencyPair, kask, kbid string, r ProfitStruct) {
+	trade, err := a.Paper.Execute(paper.Order{
+		Base:         pair.Base,
+		Quote:        pair.Quote,
+		Volume:       r.Volume,
+		BuyExchange:  kask,
+		SellExchange: kbid,
+		Asks:         a.Depths[pair.String()][kask].Asks,
+		Bids:         a.Depths[pair.String()][kbid].Bids,
+		BuyFee:       a.Exchanges[kask].GetTakerFee(),
+		SellFee:      a.Exchanges[kbid].GetTakerFee(),
+	})
+	if err != nil {
+		log.Warn("Paper trade refused:", "route", kask+"->"+kbid, "reason", err.Error())
+		return
 	}
 
-	var (
-		sellTotal, weightedSellPrice float64
+	log.Info(
+		fmt.Sprintf(
+			"paper trade %s: bought %f at %.4f (%s), sold %f at %.4f (%s), profit: %f",
+			pair, trade.Buy.Amount, trade.Buy.AvgPrice, kask, trade.Sell.Amount, trade.Sell.AvgPrice, kbid, trade.Profit,
+		), "info",
 	)
-	for j := 0; j < bidPos+1; j++ {
-		price := a.Depths[kbid].Bids[j].Price
-		amount := math.Min(maxAmount, sellTotal+a.Depths[kbid].Bids[j].Amount) - sellTotal
-		if amount <= .0 {
-			break
-		}
+	log.Info("Paper account:", "info", a.Paper.Report())
+}
 
-		sellTotal += amount
-		if weightedSellPrice == .0 || sellTotal == .0 {
-			weightedSellPrice = price
-		} else {
-			weightedSellPrice = (weightedSellPrice*(sellTotal-amount) + price*amount) / sellTotal
-		}
+// funds returns the available amount of the currency on the exchange, the
+// virtual balance is used in paper trading mode. ok is false when the
+// balance is unknown.
+func (a *ArbitrageStrategy) funds(name, currency string) (float64, bool) {
+	if a.Paper != nil {
+		return a.Paper.Funds(name, currency), true
 	}
 
-	if math.Abs(sellTotal-buyTotal) > float64(0.00001) {
-		log.Warn(fmt.Sprintf("sell_total=%v,buy_total=%v", sellTotal, buyTotal), "warn")
+	balance, ok := a.Balances[name][currency]
+	if ok {
+		return balance.Available, true
 	}
 
-	profit := sellTotal*weightedSellPrice - buyTotal*weightedBuyPrice
-	return ProfitStruct{
-		Profit:            profit,
-		Volume:            sellTotal,
-		WeightedSellPrice: weightedSellPrice,
-		WeightedBuyPrice:  weightedBuyPrice,
+	// funds of converted books are held in the source quote
+	for source, conversion := range config.Cfg.Settings.QuoteConversions {
+		if conversion.To != currency {
+			continue
+		}
+
+		if balance, ok := a.Balances[name][source]; ok {
+			return balance.Available * conversion.Rate, true
+		}
 	}
+
+	return 0, false
 }
 
-func (a *ArbitrageStrategy) Loop() {
+// Loop watches the exchanges until the context ends. A tick in progress is
+// completed, a round whose books or balances were cut short by the context
+// is not evaluated.
+func (a *ArbitrageStrategy) Loop(ctx context.Context) {
 	for {
-		a.updateDepths()
-		a.tick()
+		errs := a.updateDepths(ctx)
+		if ctx.Err() != nil {
+			return
+		}
 
-		log.Info("Refrash rate:", "info", config.Cfg.Settings.RefreshRate)
-		time.Sleep(time.Second * 5)
-	}
+		a.updateBalances(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		a.tick(time.Now(), errs)
 
-	return
+		log.Info("Refresh rate:", "info", a.RefreshRate.String())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(a.RefreshRate):
+		}
+	}
 }
@@ -0,0 +1,304 @@
+package arbitrage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"goarbitrage/config"
+	"goarbitrage/exchanges"
+	"goarbitrage/exchanges/simulated"
+	"goarbitrage/paper"
+)
+
+func book(bid, ask float64) simulated.Step {
+	return simulated.Step{
+		Bids: []exchange.ItemBook{{Price: bid, Amount: 1}},
+		Asks: []exchange.ItemBook{{Price: ask, Amount: 1}},
+	}
+}
+
+func newTestArbitrage(t *testing.T, exchanges ...*simulated.Simulated) *ArbitrageStrategy {
+	settings := config.Cfg.Settings
+	config.Cfg.Settings = config.Settings{MaxTxVolume: 10, MinTxVolume: 0.01}
+	t.Cleanup(func() { config.Cfg.Settings = settings })
+
+	a, err := New("")
+	if err != nil {
+		t.Fatalf("Test failed. New() error: %s", err)
+	}
+
+	a.DepthTimeout = 200 * time.Millisecond
+	a.Exchanges = map[string]exchange.IBotExchange{}
+	for _, e := range exchanges {
+		a.Exchanges[e.GetName()] = e
+	}
+
+	return a
+}
+
+func TestTick(t *testing.T) {
+	tests := []struct {
+		name    string
+		beta    simulated.Step
+		books   int
+		volume  float64
+		failed  bool
+		timeout bool
+	}{
+		{"crossed", book(110, 111), 2, 1, false, false},
+		{"not crossed", book(99, 101), 2, 0, false, false},
+		{"empty book", simulated.Step{}, 2, 0, false, false},
+		{"error", simulated.Step{Error: "maintenance"}, 1, 0, true, false},
+		{"timeout", simulated.Step{LatencyMs: 1000, Bids: book(110, 111).Bids}, 1, 0, true, true},
+	}
+
+	for _, test := range tests {
+		alpha := simulated.New("Alpha")
+		alpha.SetSteps("BTC/USD", book(99, 100))
+		beta := simulated.New("Beta")
+		beta.SetSteps("BTC/USD", test.beta)
+
+		a := newTestArbitrage(t, alpha, beta)
+		errs := a.updateDepths(context.Background())
+
+		if len(a.Depths["BTC/USD"]) != test.books {
+			t.Errorf("Test failed. %s: expected %d books. Actual %d", test.name, test.books, len(a.Depths["BTC/USD"]))
+		}
+
+		if !test.failed && len(errs) != 0 {
+			t.Errorf("Test failed. %s: expected no error. Actual %v", test.name, errs)
+		}
+
+		var err *exchange.DepthError
+		if test.failed && (len(errs) != 1 || !errors.As(errs[0], &err) || err.Exchange != "Beta" || err.Timeout() != test.timeout) {
+			t.Errorf("Test failed. %s: unexpected errors: %v", test.name, errs)
+		}
+
+		decisions := a.tick(time.Now(), errs)
+		if test.volume == 0 {
+			if len(decisions) != 0 {
+				t.Errorf("Test failed. %s: expected no decision. Actual %+v", test.name, decisions)
+			}
+			continue
+		}
+
+		if len(decisions) != 1 {
+			t.Errorf("Test failed. %s: expected 1 decision. Actual %+v", test.name, decisions)
+			continue
+		}
+
+		d := decisions[0]
+		if d.Buy != "Alpha" || d.Sell != "Beta" || d.Pair.String() != "BTC/USD" || d.Volume != test.volume {
+			t.Errorf("Test failed. %s: unexpected decision: %+v", test.name, d)
+		}
+	}
+}
+
+func TestDepthDeadline(t *testing.T) {
+	alpha := simulated.New("Alpha")
+	alpha.SetSteps("BTC/USD", book(99, 100))
+	beta := simulated.New("Beta")
+	beta.SetSteps("BTC/USD", book(110, 111), simulated.Step{LatencyMs: 1000})
+	beta.DepthTimeout = 20 * time.Millisecond
+
+	a := newTestArbitrage(t, alpha, beta)
+	a.DepthTimeout = time.Second
+	if errs := a.updateDepths(context.Background()); len(errs) != 0 || len(a.Depths["BTC/USD"]) != 2 {
+		t.Fatalf("Test failed. Expected 2 books. Actual %v, %v", a.Depths, errs)
+	}
+
+	start := time.Now()
+	errs := a.updateDepths(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Test failed. Expected the deadline of Beta to cut the update short. Actual %s", elapsed)
+	}
+
+	if len(errs) != 1 || !errs[0].(*exchange.DepthError).Timeout() {
+		t.Errorf("Test failed. Expected Beta timeout. Actual %v", errs)
+	}
+
+	if _, ok := a.Depths["BTC/USD"]["Beta"]; ok {
+		t.Error("Test failed. Expected the stale Beta book to be dropped")
+	}
+}
+
+func TestPollingDelay(t *testing.T) {
+	alpha := simulated.New("Alpha")
+	alpha.SetSteps("BTC/USD", book(99, 100), book(98, 99))
+	beta := simulated.New("Beta")
+	beta.SetSteps("BTC/USD", book(110, 111), book(100, 101))
+	beta.RESTPollingDelay = time.Hour
+
+	a := newTestArbitrage(t, alpha, beta)
+	a.updateDepths(context.Background())
+	a.updateDepths(context.Background())
+
+	books := a.Depths["BTC/USD"]
+	if books["Alpha"].Asks[0].Price != 99 || books["Beta"].Asks[0].Price != 111 {
+		t.Errorf("Test failed. Expected Beta to keep its first book. Actual %+v", books)
+	}
+}
+
+func TestGetProfitFor(t *testing.T) {
+	alpha := simulated.New("Alpha")
+	alpha.TakerFee = 0.2
+	beta := simulated.New("Beta")
+	beta.TakerFee = 0.5
+
+	a := newTestArbitrage(t, alpha, beta)
+	s := &spreadEvaluation{
+		pair:      exchange.NewCurrencyPair("BTC", "USD"),
+		exchanges: a.Exchanges,
+		books: map[string]exchange.OrderBook{
+			"Alpha": {Asks: []exchange.ItemBook{{Price: 100, Amount: 1}, {Price: 101, Amount: 1}}},
+			"Beta":  {Bids: []exchange.ItemBook{{Price: 110, Amount: 1.5}}},
+		},
+	}
+
+	// 1.5 bought at 100.333 on average for 150.5 and sold at 110 for 165
+	p := s.getProfitFor(1, 0, "Alpha", "Beta")
+	expected := ProfitStruct{
+		GrossProfit:       14.5,
+		BuyFee:            0.301,
+		SellFee:           0.825,
+		NetProfit:         13.374,
+		Volume:            1.5,
+		WeightedBuyPrice:  150.5 / 1.5,
+		WeightedSellPrice: 110,
+	}
+
+	actual := []float64{p.GrossProfit, p.BuyFee, p.SellFee, p.NetProfit, p.Volume, p.WeightedBuyPrice, p.WeightedSellPrice}
+	for i, value := range []float64{expected.GrossProfit, expected.BuyFee, expected.SellFee, expected.NetProfit, expected.Volume, expected.WeightedBuyPrice, expected.WeightedSellPrice} {
+		if math.Abs(actual[i]-value) > 0.000001 {
+			t.Fatalf("Test failed. Expected %+v. Actual %+v", expected, p)
+		}
+	}
+}
+
+func TestLotSteps(t *testing.T) {
+	alpha := simulated.New("Alpha")
+	alpha.LotStep = 0.004
+	alpha.SetSteps("BTC/USD", simulated.Step{Asks: []exchange.ItemBook{{Price: 100, Amount: 0.05}}})
+	beta := simulated.New("Beta")
+	beta.LotStep = 0.01
+	beta.SetSteps("BTC/USD", simulated.Step{Bids: []exchange.ItemBook{{Price: 110, Amount: 0.05}}})
+
+	a := newTestArbitrage(t, alpha, beta)
+	a.updateDepths(context.Background())
+
+	// 0.05 is a lot on Beta only, 0.04 is the largest lot on both
+	decisions := a.tick(time.Now(), nil)
+	if len(decisions) != 1 || math.Abs(decisions[0].Volume-0.04) > 1e-9 {
+		t.Errorf("Test failed. Expected volume 0.04. Actual %+v", decisions)
+	}
+}
+
+func TestStaleBooks(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tests := []struct {
+		name      string
+		received  time.Time
+		timestamp time.Time
+		books     int
+		decisions int
+	}{
+		{"fresh", now, time.Time{}, 2, 1},
+		{"old", now.Add(-time.Minute), time.Time{}, 1, 0},
+		{"old exchange time", now, now.Add(-time.Minute), 2, 1},
+		{"skewed with same exchange time", now.Add(-10 * time.Second), now, 2, 0},
+	}
+
+	for _, test := range tests {
+		a := newTestArbitrage(t, simulated.New("Alpha"), simulated.New("Beta"))
+		alpha := book(99, 100)
+		alpha.Bids[0].Timestamp = float64(now.Unix())
+		beta := book(110, 111)
+		if !test.timestamp.IsZero() {
+			beta.Bids[0].Timestamp = float64(test.timestamp.Unix())
+		}
+
+		a.setDepth("Alpha", "BTC/USD", exchange.OrderBook{Bids: alpha.Bids, Asks: alpha.Asks}, now)
+		a.setDepth("Beta", "BTC/USD", exchange.OrderBook{Bids: beta.Bids, Asks: beta.Asks}, test.received)
+
+		stored := a.Depths["BTC/USD"]["Beta"]
+		if !stored.Received.Equal(test.received) || !stored.Timestamp.Equal(test.timestamp) {
+			t.Errorf("Test failed. %s: expected times %s and %s. Actual %s and %s", test.name, test.received, test.timestamp, stored.Received, stored.Timestamp)
+		}
+
+		decisions := a.tick(now, nil)
+		if len(a.Depths["BTC/USD"]) != test.books || len(decisions) != test.decisions {
+			t.Errorf("Test failed. %s: expected %d books and %d decisions. Actual %d and %+v", test.name, test.books, test.decisions, len(a.Depths["BTC/USD"]), decisions)
+		}
+	}
+}
+
+func TestLoopStops(t *testing.T) {
+	alpha := simulated.New("Alpha")
+	alpha.SetSteps("BTC/USD", simulated.Step{LatencyMs: 1000})
+
+	a := newTestArbitrage(t, alpha)
+	a.DepthTimeout = 5 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	a.Loop(ctx)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Test failed. Expected the loop to stop with the context. Actual %s", elapsed)
+	}
+}
+
+func TestBalancesLimitVolume(t *testing.T) {
+	alpha := simulated.New("Alpha")
+	alpha.SetSteps("BTC/USD", book(99, 100))
+	alpha.SetBalance("USD", 50)
+	beta := simulated.New("Beta")
+	beta.SetSteps("BTC/USD", book(110, 111))
+	beta.SetBalance("BTC", 1)
+
+	a := newTestArbitrage(t, alpha, beta)
+	a.updateDepths(context.Background())
+	a.updateBalances(context.Background())
+
+	decisions := a.tick(time.Now(), nil)
+	if len(decisions) != 1 {
+		t.Fatalf("Test failed. Expected 1 decision. Actual %+v", decisions)
+	}
+
+	expected := 50 / (100 * 1.002)
+	if volume := decisions[0].Volume; volume > expected || math.Abs(volume-expected) > 0.0001 {
+		t.Errorf("Test failed. Expected volume %f. Actual %f", expected, volume)
+	}
+}
+
+func TestPaperTrade(t *testing.T) {
+	alpha := simulated.New("Alpha")
+	alpha.SetSteps("BTC/USD", book(99, 100), book(99, 100), book(99, 100))
+	beta := simulated.New("Beta")
+	beta.SetSteps("BTC/USD", book(110, 111), book(100.5, 101), book(110, 111))
+
+	a := newTestArbitrage(t, alpha, beta)
+	a.Paper = paper.New(map[string]map[string]float64{
+		"Alpha": {"USD": 1000},
+		"Beta":  {"BTC": 1},
+	})
+
+	for i := 0; i < 3; i++ {
+		a.updateDepths(context.Background())
+		a.tick(time.Now(), nil)
+	}
+
+	// the second step isn't profitable after fees and the third one finds
+	// the inventory moved by the first trade
+	if a.Paper.Trades() != 1 {
+		t.Errorf("Test failed. Expected 1 paper trade. Actual %d", a.Paper.Trades())
+	}
+
+	if a.Paper.Balance("Beta", "BTC") != 0 || a.Paper.PnL()["USD"] <= 0 {
+		t.Errorf("Test failed. Unexpected paper account: %s", a.Paper.Report())
+	}
+}
@@ -0,0 +1,147 @@
+package arbitrage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/exchanges"
+	"goarbitrage/recorder"
+)
+
+var (
+	// SpreadBuckets are the upper bounds in percent of the spread
+	// distribution, the last bucket holds everything above
+	SpreadBuckets = []float64{0.1, 0.25, 0.5, 1, 2, 5}
+)
+
+type (
+	RouteSummary struct {
+		Opportunities int
+		Profit        float64
+	}
+
+	// BacktestSummary reports a replay, SkippedFiles counts the recorded
+	// files that couldn't be read and is set by the caller
+	BacktestSummary struct {
+		SkippedFiles  int
+		Records       int
+		Snapshots     int
+		Opportunities int
+		TotalProfit   float64
+		Routes        map[string]*RouteSummary
+		Spreads       []int
+	}
+)
+
+// Backtest replays recorded order books in the given order without touching
+// the network. Books are collected until every exchange and symbol of the
+// current snapshot has been seen once, a repeated one closes the snapshot and
+// triggers a tick at the receive time of the newest record.
+func (a *ArbitrageStrategy) Backtest(records []recorder.Record) BacktestSummary {
+	summary := BacktestSummary{
+		Routes:  map[string]*RouteSummary{},
+		Spreads: make([]int, len(SpreadBuckets)+1),
+	}
+
+	var now time.Time
+	skipped := map[string]bool{}
+	pending := map[string]bool{}
+	for _, r := range records {
+		key := r.Exchange + ":" + r.Symbol
+		ex, ok := a.Exchanges[r.Exchange]
+		if !ok || !isEnabled(ex, r.Symbol) {
+			if !skipped[key] {
+				log.Warn("Backtest skips records of", "exchange", r.Exchange, "symbol", r.Symbol)
+				skipped[key] = true
+			}
+			continue
+		}
+
+		if pending[key] {
+			a.backtestTick(&summary, now)
+			pending = map[string]bool{}
+		}
+
+		summary.Records++
+		pending[key] = true
+		a.setDepth(r.Exchange, r.Symbol, exchange.OrderBook{
+			Bids: r.Bids,
+			Asks: r.Asks,
+		}, r.Received)
+
+		if r.Received.After(now) {
+			now = r.Received
+		}
+	}
+
+	if len(pending) > 0 {
+		a.backtestTick(&summary, now)
+	}
+
+	return summary
+}
+
+// isEnabled reports whether the exchange polls the canonical pair
+func isEnabled(e exchange.IBotExchange, symbol string) bool {
+	for _, pair := range e.GetEnabledPairs() {
+		if pair.String() == symbol {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *ArbitrageStrategy) backtestTick(summary *BacktestSummary, now time.Time) {
+	summary.Snapshots++
+
+	for _, o := range a.tick(now, nil) {
+		summary.Opportunities++
+		summary.TotalProfit += o.NetProfit
+
+		route := o.Pair.String() + " " + o.Buy + "->" + o.Sell
+		if summary.Routes[route] == nil {
+			summary.Routes[route] = &RouteSummary{}
+		}
+		summary.Routes[route].Opportunities++
+		summary.Routes[route].Profit += o.NetProfit
+
+		bucket := sort.SearchFloat64s(SpreadBuckets, o.Spread)
+		summary.Spreads[bucket]++
+	}
+}
+
+func (s BacktestSummary) String() string {
+	lines := []string{
+		fmt.Sprintf("records: %d, snapshots: %d, skipped files: %d", s.Records, s.Snapshots, s.SkippedFiles),
+		fmt.Sprintf("opportunities: %d, total profit: %f", s.Opportunities, s.TotalProfit),
+	}
+
+	routes := make([]string, 0, len(s.Routes))
+	for route := range s.Routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	for _, route := range routes {
+		r := s.Routes[route]
+		lines = append(lines, fmt.Sprintf("route %s: opportunities: %d, profit: %f", route, r.Opportunities, r.Profit))
+	}
+
+	lower := "0"
+	for i, count := range s.Spreads {
+		if i < len(SpreadBuckets) {
+			upper := fmt.Sprint(SpreadBuckets[i])
+			lines = append(lines, fmt.Sprintf("spread %s%%-%s%%: %d", lower, upper, count))
+			lower = upper
+		} else {
+			lines = append(lines, fmt.Sprintf("spread >%s%%: %d", lower, count))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
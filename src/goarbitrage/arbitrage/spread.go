@@ -0,0 +1,318 @@
+package arbitrage
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/config"
+	"goarbitrage/exchanges"
+)
+
+const (
+	SPREAD_STRATEGY = "spread"
+)
+
+type (
+	// SpreadStrategy buys on one exchange and sells on another whenever the
+	// asks of the first are below the bids of the second, only books of the
+	// same pair are compared. It holds no state and may evaluate several
+	// snapshots at once.
+	SpreadStrategy struct{}
+
+	// spreadEvaluation is the evaluation of the books of a single pair of a
+	// snapshot
+	spreadEvaluation struct {
+		pair      exchange.CurrencyPair
+		books     map[string]exchange.OrderBook
+		exchanges map[string]exchange.IBotExchange
+		fundsFunc func(name, currency string) (float64, bool)
+	}
+)
+
+func init() {
+	RegisterStrategy(SPREAD_STRATEGY, func() Strategy {
+		return &SpreadStrategy{}
+	})
+}
+
+func (s *SpreadStrategy) Name() string {
+	return SPREAD_STRATEGY
+}
+
+func (s *SpreadStrategy) Evaluate(snapshot Snapshot) []Decision {
+	var decisions []Decision
+
+	for symbol, books := range snapshot.Books {
+		pair, err := exchange.ParseCurrencyPair(symbol)
+		if err != nil {
+			log.Warn("Books skipped:", "warn", err.Error())
+			continue
+		}
+
+		e := &spreadEvaluation{
+			pair:      pair,
+			books:     books,
+			exchanges: snapshot.Exchanges,
+			fundsFunc: snapshot.Funds,
+		}
+		decisions = append(decisions, e.evaluatePair()...)
+	}
+
+	return decisions
+}
+
+func (s *spreadEvaluation) evaluatePair() []Decision {
+	var decisions []Decision
+
+	for k1, _ := range s.books {
+		for k2, _ := range s.books {
+			if k1 == k2 {
+				continue
+			}
+
+			ex1 := s.books[k1]
+			ex2 := s.books[k2]
+			if len(ex1.Asks) == 0 || len(ex2.Bids) == 0 {
+				continue
+			}
+
+			if ex1.Asks[0].Price < ex2.Bids[0].Price {
+				if o, ok := s.arbitrageOpportunity(k1, k2); ok {
+					decisions = append(decisions, o)
+				}
+			}
+		}
+	}
+
+	return decisions
+}
+
+func (s *spreadEvaluation) arbitrageOpportunity(kask, kbid string) (Decision, bool) {
+	r := s.arbitrageDepthOpportunity(kask, kbid)
+	if r.Rejected != "" {
+		log.Info("Opportunity rejected:", "pair", s.pair.String(), "route", kask+"->"+kbid, "reason", r.Rejected)
+		return Decision{}, false
+	}
+
+	if r.Volume == 0 || r.BuyPrice == 0 {
+		return Decision{}, false
+	}
+
+	perc := r.NetProfit / (r.Volume * r.WeightedBuyPrice) * 100
+	log.Info("Percent:", "info", perc)
+
+	settings := config.Cfg.Settings
+	if r.NetProfit > settings.ProfitThresh && perc > settings.PercThresh {
+		return Decision{Pair: s.pair, Buy: kask, Sell: kbid, Spread: perc, ProfitStruct: r}, true
+	}
+
+	return Decision{}, false
+}
+
+func (s *spreadEvaluation) arbitrageDepthOpportunity(kask, kbid string) ProfitStruct {
+	var (
+		profit                 ProfitStruct
+		bestAskPos, bestBidPos int
+		rejected               string
+	)
+
+	askPos, bidPos := s.getMaxDepth(kask, kbid)
+	for i := 0; i < askPos+1; i++ {
+		for j := 0; j < bidPos+1; j++ {
+			tempProfit := s.getProfitFor(i, j, kask, kbid)
+			if tempProfit.Rejected != "" {
+				rejected = tempProfit.Rejected
+				continue
+			}
+
+			if tempProfit.NetProfit > 0 && tempProfit.NetProfit >= profit.NetProfit {
+				profit = tempProfit
+				bestAskPos, bestBidPos = i, j
+			}
+		}
+	}
+
+	if profit.Volume == 0 && rejected != "" {
+		return ProfitStruct{Rejected: rejected}
+	}
+
+	profit.BuyPrice = s.books[kask].Asks[bestAskPos].Price
+	profit.SellPrice = s.books[kbid].Bids[bestBidPos].Price
+	return profit
+}
+
+func (s *spreadEvaluation) getMaxDepth(kask, kbid string) (int, int) {
+	var (
+		askPos, bidPos int
+	)
+
+	if len(s.books[kbid].Bids) > 0 && len(s.books[kask].Asks) > 0 {
+		for s.books[kask].Asks[askPos].Price < s.books[kbid].Bids[0].Price {
+			if askPos >= len(s.books[kask].Asks)-1 {
+				break
+			}
+
+			askPos += 1
+		}
+
+		for s.books[kask].Asks[0].Price < s.books[kbid].Bids[bidPos].Price {
+			if bidPos >= len(s.books[kbid].Bids)-1 {
+				break
+			}
+
+			bidPos += 1
+		}
+	}
+
+	return askPos, bidPos
+}
+
+func (s *spreadEvaluation) getProfitFor(askPos, bidPos int, kask, kbid string) ProfitStruct {
+	if s.books[kask].Asks[askPos].Price >= s.books[kbid].Bids[bidPos].Price {
+		return ProfitStruct{}
+	}
+
+	var (
+		maxAmountBuy, maxAmountSell float64
+	)
+
+	for i := 0; i < askPos+1; i++ {
+		maxAmountBuy += s.books[kask].Asks[i].Amount
+	}
+
+	for j := 0; j < bidPos+1; j++ {
+		maxAmountSell += s.books[kbid].Bids[j].Amount
+	}
+
+	maxAmount, err := s.sizeVolume(math.Min(maxAmountBuy, maxAmountSell), s.fundsLimit(askPos, kask, kbid), kask, kbid)
+	if err != nil {
+		return ProfitStruct{Rejected: err.Error()}
+	}
+
+	var (
+		buyTotal, weightedBuyPrice float64
+	)
+	for i := 0; i < askPos+1; i++ {
+		price := s.books[kask].Asks[i].Price
+		amount := math.Min(maxAmount, buyTotal+s.books[kask].Asks[i].Amount) - buyTotal
+		if amount <= .0 {
+			break
+		}
+
+		buyTotal += amount
+		if weightedBuyPrice == .0 {
+			weightedBuyPrice = price
+		} else {
+			weightedBuyPrice = (weightedBuyPrice*(buyTotal-amount) + price*amount) / buyTotal
+		}
+	}
+
+	var (
+		sellTotal, weightedSellPrice float64
+	)
+	for j := 0; j < bidPos+1; j++ {
+		price := s.books[kbid].Bids[j].Price
+		amount := math.Min(maxAmount, sellTotal+s.books[kbid].Bids[j].Amount) - sellTotal
+		if amount <= .0 {
+			break
+		}
+
+		sellTotal += amount
+		if weightedSellPrice == .0 || sellTotal == .0 {
+			weightedSellPrice = price
+		} else {
+			weightedSellPrice = (weightedSellPrice*(sellTotal-amount) + price*amount) / sellTotal
+		}
+	}
+
+	if math.Abs(sellTotal-buyTotal) > float64(0.00001) {
+		log.Warn(fmt.Sprintf("sell_total=%v,buy_total=%v", sellTotal, buyTotal), "warn")
+	}
+
+	buyCost := buyTotal * weightedBuyPrice
+	sellCost := sellTotal * weightedSellPrice
+	buyFee := common.CalculateFee(buyCost, s.exchanges[kask].GetTakerFee())
+	sellFee := common.CalculateFee(sellCost, s.exchanges[kbid].GetTakerFee())
+
+	profit := sellCost - buyCost
+	return ProfitStruct{
+		GrossProfit:       profit,
+		BuyFee:            buyFee,
+		SellFee:           sellFee,
+		NetProfit:         profit - buyFee - sellFee,
+		Volume:            sellTotal,
+		WeightedSellPrice: weightedSellPrice,
+		WeightedBuyPrice:  weightedBuyPrice,
+	}
+}
+
+// funds returns the available amount of the currency on the exchange, ok is
+// false when the balance is unknown
+func (s *spreadEvaluation) funds(name, currency string) (float64, bool) {
+	if s.fundsFunc == nil {
+		return 0, false
+	}
+
+	return s.fundsFunc(name, currency)
+}
+
+// fundsLimit returns the volume that can be bought with the quote funds on
+// the buy exchange walking the asks up to askPos and sold with the base funds
+// on the sell exchange
+func (s *spreadEvaluation) fundsLimit(askPos int, kask, kbid string) float64 {
+	limit := math.Inf(1)
+
+	if baseFunds, ok := s.funds(kbid, s.pair.Base); ok {
+		limit = baseFunds
+	}
+
+	quoteFunds, ok := s.funds(kask, s.pair.Quote)
+	if !ok {
+		return limit
+	}
+
+	var affordable float64
+	feeRate := 1 + s.exchanges[kask].GetTakerFee()/100
+	for i := 0; i < askPos+1; i++ {
+		level := s.books[kask].Asks[i]
+		cost := level.Price * level.Amount * feeRate
+		if cost >= quoteFunds {
+			affordable += quoteFunds / (level.Price * feeRate)
+			return math.Min(limit, affordable)
+		}
+
+		affordable += level.Amount
+		quoteFunds -= cost
+	}
+
+	return limit
+}
+
+// sizeVolume clamps the volume available on both books to the configured
+// limits and the funds, then rounds it down to a lot valid on both exchanges
+func (s *spreadEvaluation) sizeVolume(volume, funds float64, kask, kbid string) (float64, error) {
+	settings := config.Cfg.Settings
+	volume = math.Min(volume, settings.MaxTxVolume)
+
+	if funds < volume {
+		if funds < settings.MinTxVolume {
+			return 0, fmt.Errorf("available funds cover only %v, below min_tx_volume %v", funds, settings.MinTxVolume)
+		}
+		volume = funds
+	}
+
+	step := common.CommonStep(s.exchanges[kask].GetLotStep(), s.exchanges[kbid].GetLotStep())
+	volume = common.FloorToStep(volume, step)
+	if volume <= 0 {
+		return 0, fmt.Errorf("volume rounds to zero with lot step %v", step)
+	}
+
+	if volume < settings.MinTxVolume {
+		return 0, fmt.Errorf("volume %v is below min_tx_volume %v", volume, settings.MinTxVolume)
+	}
+
+	return volume, nil
+}
@@ -0,0 +1,71 @@
+package arbitrage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"goarbitrage/exchanges"
+)
+
+type (
+	// Snapshot is the market state handed to a strategy on every tick. Books
+	// are grouped by canonical pair then exchange name, Funds returns the available
+	// amount of a currency on an exchange and false when it is unknown. Errors
+	// holds an *exchange.DepthError for every book missing from this update,
+	// books older than the configured age are evicted beforehand.
+	Snapshot struct {
+		Books     map[string]map[string]exchange.OrderBook
+		Errors    []error
+		Exchanges map[string]exchange.IBotExchange
+		Funds     func(exchangeName, currency string) (float64, bool)
+	}
+
+	// Strategy turns book snapshots into trading decisions. Implementations
+	// register themselves by name from an init function and are picked with
+	// the strategy setting of the config.
+	Strategy interface {
+		Name() string
+		Evaluate(snapshot Snapshot) []Decision
+	}
+
+	StrategyFactory func() Strategy
+)
+
+var (
+	strategiesMu sync.Mutex
+	strategies   = map[string]StrategyFactory{}
+)
+
+func RegisterStrategy(name string, factory StrategyFactory) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+
+	if _, ok := strategies[name]; ok {
+		panic("arbitrage: strategy registered twice: " + name)
+	}
+
+	strategies[name] = factory
+}
+
+func NewStrategy(name string) (Strategy, error) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+
+	factory, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q, available: %v", name, strategyNames())
+	}
+
+	return factory(), nil
+}
+
+func strategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
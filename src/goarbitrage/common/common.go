@@ -9,14 +9,10 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"hash"
-	"io"
 	"io/ioutil"
-	"log"
 	"math"
-	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -128,6 +124,18 @@ func DataContains(haystack []string, needle string) bool {
 	return strings.Contains(data, needle)
 }
 
+// StringDataCompare reports whether the needle is one of the haystack
+// entries, unlike DataContains it doesn't match substrings
+func StringDataCompare(haystack []string, needle string) bool {
+	for _, i := range haystack {
+		if i == needle {
+			return true
+		}
+	}
+
+	return false
+}
+
 func JoinStrings(input []string, seperator string) string {
 	return strings.Join(input, seperator)
 }
@@ -169,6 +177,48 @@ func RoundFloat(x float64, prec int) float64 {
 	return rounder / pow
 }
 
+// FloorToStep rounds x down to the nearest multiple of step
+func FloorToStep(x, step float64) float64 {
+	if step <= 0 {
+		return x
+	}
+
+	result := math.Floor(x/step+1e-9) * step
+	prec := int(math.Ceil(-math.Log10(step)))
+	if prec > 0 {
+		result = RoundFloat(result, prec)
+	}
+
+	return result
+}
+
+// CommonStep returns the smallest step both steps divide, a lot of that size
+// is valid on two exchanges with these steps. Steps are taken with at most 8
+// decimals, a step that isn't positive leaves the other one.
+func CommonStep(a, b float64) float64 {
+	if a <= 0 {
+		return b
+	}
+	if b <= 0 {
+		return a
+	}
+
+	const scale = 1e8
+	x, y := int64(math.Round(a*scale)), int64(math.Round(b*scale))
+	if x == 0 || y == 0 {
+		return math.Max(a, b)
+	}
+
+	gcd := func(m, n int64) int64 {
+		for n != 0 {
+			m, n = n, m%n
+		}
+		return m
+	}
+
+	return RoundFloat(float64(x/gcd(x, y)*y)/scale, 8)
+}
+
 func IsEnabled(isEnabled bool) string {
 	if isEnabled {
 		return "Enabled"
@@ -197,67 +247,6 @@ func CalculateNetProfit(amount, priceThen, priceNow, costs float64) float64 {
 	return (priceNow * amount) - (priceThen * amount) - costs
 }
 
-func SendHTTPRequest(method, path string, headers map[string]string, body io.Reader) (string, error) {
-	result := strings.ToUpper(method)
-	if result != "POST" && result != "GET" && result != "DELETE" {
-		return "", errors.New("Invalid HTTP method specified.")
-	}
-
-	req, err := http.NewRequest(method, path, body)
-	if err != nil {
-		return "", err
-	}
-
-	for k, v := range headers {
-		req.Header.Add(k, v)
-	}
-
-	httpClient := &http.Client{}
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-
-	defer resp.Body.Close()
-	contents, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	return string(contents), nil
-}
-
-func SendHTTPGetRequest(url string, jsonDecode bool, result interface{}) (err error) {
-	res, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-
-	if res.StatusCode != 200 {
-		log.Printf("HTTP status code: %d\n", res.StatusCode)
-		return errors.New("Status code was not 200.")
-	}
-
-	contents, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return err
-	}
-
-	defer res.Body.Close()
-
-	if jsonDecode {
-		err := JSONDecode(contents, &result)
-		if err != nil {
-			log.Println(string(contents[:]))
-			return err
-		}
-	} else {
-		result = &contents
-	}
-
-	return nil
-}
-
 func JSONEncode(v interface{}) ([]byte, error) {
 	return json.Marshal(v)
 }
@@ -146,6 +146,17 @@ func TestDataContains(t *testing.T) {
 	}
 }
 
+func TestStringDataCompare(t *testing.T) {
+	t.Parallel()
+	haystack := []string{"BTC/USDT", "ETH/BTC"}
+	if !StringDataCompare(haystack, "ETH/BTC") {
+		t.Error("Test failed. Expected 'true'. Actual 'false'")
+	}
+	if StringDataCompare(haystack, "BTC/USD") {
+		t.Error("Test failed. Expected 'false'. Actual 'true'")
+	}
+}
+
 func TestJoinStrings(t *testing.T) {
 	t.Parallel()
 	originalInputOne := []string{"hello", "moto"}
@@ -179,6 +190,47 @@ func TestRoundFloat(t *testing.T) {
 	}
 }
 
+func TestFloorToStep(t *testing.T) {
+	t.Parallel()
+	originalInput := float64(0.123456789)
+	stepInput := float64(0.001)
+	expectedOutput := float64(0.123)
+	actualResult := FloorToStep(originalInput, stepInput)
+	if expectedOutput != actualResult {
+		t.Error(fmt.Sprintf("Test failed. Expected '%f'. Actual '%f'.", expectedOutput, actualResult))
+	}
+
+	expectedOutput = float64(0.3)
+	actualResult = FloorToStep(0.3, 0.1)
+	if expectedOutput != actualResult {
+		t.Error(fmt.Sprintf("Test failed. Expected '%f'. Actual '%f'.", expectedOutput, actualResult))
+	}
+
+	expectedOutput = float64(1.5)
+	actualResult = FloorToStep(1.5, 0)
+	if expectedOutput != actualResult {
+		t.Error(fmt.Sprintf("Test failed. Expected '%f'. Actual '%f'.", expectedOutput, actualResult))
+	}
+}
+
+func TestCommonStep(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b, expected float64
+	}{
+		{0.004, 0.01, 0.02},
+		{0.001, 0.01, 0.01},
+		{0.00000001, 0.001, 0.001},
+		{0.5, 0, 0.5},
+	}
+
+	for _, test := range tests {
+		if actual := CommonStep(test.a, test.b); actual != test.expected {
+			t.Errorf("Test failed. Expected '%v' for %v and %v. Actual '%v'.", test.expected, test.a, test.b, actual)
+		}
+	}
+}
+
 func TestCalculateFee(t *testing.T) {
 	t.Parallel()
 	originalInput := float64(1)
@@ -0,0 +1,163 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	HTTP_TIMEOUT                 = 15 * time.Second
+	HTTP_DIAL_TIMEOUT            = 10 * time.Second
+	HTTP_IDLE_TIMEOUT            = 90 * time.Second
+	HTTP_MAX_IDLE_CONNS_PER_HOST = 10
+)
+
+var (
+	// DefaultHTTPClient is shared by every exchange without a client of its
+	// own so that connections to the same host are reused
+	DefaultHTTPClient = NewHTTPClient(nil, HTTP_TIMEOUT)
+)
+
+type (
+	// HTTPClient sends requests through a long lived http.Client, every
+	// request is bounded by Timeout on top of the deadline of its context
+	HTTPClient struct {
+		Client  *http.Client
+		Timeout time.Duration
+	}
+
+	// HTTPError is returned for responses outside the 2xx range
+	HTTPError struct {
+		Method     string
+		URL        string
+		StatusCode int
+		Body       string
+	}
+)
+
+// NewHTTPClient wraps the client, a nil client gets a transport keeping idle
+// connections open for reuse. A zero timeout leaves requests bounded by
+// their context only.
+func NewHTTPClient(client *http.Client, timeout time.Duration) *HTTPClient {
+	if client == nil {
+		client = &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   HTTP_DIAL_TIMEOUT,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				MaxIdleConnsPerHost: HTTP_MAX_IDLE_CONNS_PER_HOST,
+				IdleConnTimeout:     HTTP_IDLE_TIMEOUT,
+				TLSHandshakeTimeout: HTTP_DIAL_TIMEOUT,
+			},
+		}
+	}
+
+	return &HTTPClient{
+		Client:  client,
+		Timeout: timeout,
+	}
+}
+
+// WithTimeout returns a client sharing the connections of c with another
+// request timeout
+func (c *HTTPClient) WithTimeout(timeout time.Duration) *HTTPClient {
+	return &HTTPClient{
+		Client:  c.Client,
+		Timeout: timeout,
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s %s: HTTP status code %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// SendRequest sends the request and returns the response body. Responses
+// outside the 2xx range return the body together with an *HTTPError.
+func (c *HTTPClient) SendRequest(ctx context.Context, method, path string, headers map[string]string, body io.Reader) (string, error) {
+	result := StringToUpper(method)
+	if result != "POST" && result != "GET" && result != "DELETE" {
+		return "", errors.New("Invalid HTTP method specified.")
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequest(result, path, body)
+	if err != nil {
+		return "", redactError(err)
+	}
+	req = req.WithContext(ctx)
+
+	for k, v := range headers {
+		req.Header.Add(k, v)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return "", redactError(err)
+	}
+
+	defer resp.Body.Close()
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return string(contents), &HTTPError{
+			Method:     result,
+			URL:        redactQuery(path),
+			StatusCode: resp.StatusCode,
+			Body:       string(contents),
+		}
+	}
+
+	return string(contents), nil
+}
+
+// redactQuery drops the query of a URL, it may carry signatures which must
+// stay out of errors and logs
+func redactQuery(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		return path[:i]
+	}
+
+	return path
+}
+
+// redactError drops the query from the URL of transport errors
+func redactError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		urlErr.URL = redactQuery(urlErr.URL)
+	}
+
+	return err
+}
+
+// SendGetRequest fetches the URL and decodes the JSON response into result
+func (c *HTTPClient) SendGetRequest(ctx context.Context, path string, result interface{}) error {
+	resp, err := c.SendRequest(ctx, "GET", path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	err = JSONDecode([]byte(resp), result)
+	if err != nil {
+		return fmt.Errorf("Unable to JSON Unmarshal response of %s: %s", path, err)
+	}
+
+	return nil
+}
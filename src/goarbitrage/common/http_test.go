@@ -0,0 +1,71 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte(`{"price":"10.5"}`))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"Invalid nonce"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.Client(), time.Second)
+
+	result := struct {
+		Price float64 `json:"price,string"`
+	}{}
+	if err := client.SendGetRequest(context.Background(), server.URL+"/ok", &result); err != nil || result.Price != 10.5 {
+		t.Errorf("Test failed. Expected 10.5. Actual %v, %v", result.Price, err)
+	}
+
+	body, err := client.SendRequest(context.Background(), "POST", server.URL+"/order?signature=secret", nil, nil)
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("Test failed. Expected *HTTPError. Actual %v", err)
+	}
+
+	if httpErr.StatusCode != http.StatusBadRequest || httpErr.Body != `{"message":"Invalid nonce"}` || body != httpErr.Body {
+		t.Errorf("Test failed. Unexpected error: %+v", httpErr)
+	}
+
+	if httpErr.URL != server.URL+"/order" {
+		t.Errorf("Test failed. Expected query to be dropped. Actual %s", httpErr.URL)
+	}
+
+	_, err = client.SendRequest(context.Background(), "GET", "http://127.0.0.1:0/account?signature=secret", nil, nil)
+	if err == nil || StringContains(err.Error(), "secret") {
+		t.Errorf("Test failed. Expected transport error without the query. Actual %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.WithTimeout(20*time.Millisecond).SendRequest(context.Background(), "GET", server.URL+"/slow", nil, nil); err == nil {
+		t.Error("Test failed. Expected timeout error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.SendRequest(ctx, "GET", server.URL+"/ok", nil, nil); err == nil {
+		t.Error("Test failed. Expected cancelled context error")
+	}
+
+	if time.Since(start) > 150*time.Millisecond {
+		t.Errorf("Test failed. Expected requests to be cut short. Actual %s", time.Since(start))
+	}
+
+	if _, err := client.SendRequest(context.Background(), "PATCH", server.URL, nil, nil); err == nil {
+		t.Error("Test failed. Expected invalid method error")
+	}
+}
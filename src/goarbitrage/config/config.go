@@ -1,13 +1,15 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path"
+	"reflect"
 	"time"
 
 	"github.com/mgutz/logxi/v1"
 
-	"encoding/json"
 	"goarbitrage/common"
 )
 
@@ -23,55 +25,160 @@ var (
 
 type (
 	Config struct {
-		Telegram  Telegram            `json: "telegram"`
-		Exchanges map[string]Exchange `json: "exchanges"`
-		Settings  Settings            `json: "settings"`
+		Telegram  Telegram            `json:"telegram"`
+		Exchanges map[string]Exchange `json:"exchanges"`
+		Settings  Settings            `json:"settings"`
+		Paper     Paper               `json:"paper"`
+		Recorder  Recorder            `json:"recorder"`
 	}
 
 	Settings struct {
-		RefreshRate        time.Duration `json: "refresh_rate"`
-		MaxTxVolume        float64       `json: "max_tx_volume"`
-		MinTxVolume        float64       `json: "min_tx_volume"`
-		ProfitThresh       float64       `json: "profit_thresh"`
-		PercThresh         float64       `json: "perc_thresh"`
-		ArbitrageBuyQueue  int           `json: "arbitrage_buy_queue"`
-		ArbitrageSellQueue int           `json: "arbitrage_sell_queue"`
+		// RefreshRate is the pause of the watch loop between two ticks,
+		// DepthTimeout the window for fetching the books of a tick
+		RefreshRate        Duration `json:"refresh_rate"`
+		DepthTimeout       Duration `json:"depth_timeout"`
+		MaxTxVolume        float64  `json:"max_tx_volume"`
+		MinTxVolume        float64  `json:"min_tx_volume"`
+		ProfitThresh       float64  `json:"profit_thresh"`
+		PercThresh         float64  `json:"perc_thresh"`
+		ArbitrageBuyQueue  int      `json:"arbitrage_buy_queue"`
+		ArbitrageSellQueue int      `json:"arbitrage_sell_queue"`
+		Strategy           string   `json:"strategy"`
+		// MaxBookAge evicts books received longer ago, MaxBookSkew skips
+		// routes whose books were received further apart; defaults apply
+		// when zero
+		MaxBookAge  Duration `json:"max_book_age"`
+		MaxBookSkew Duration `json:"max_book_skew"`
+		// ShutdownTimeout bounds the cleanup after a signal before the exit
+		// is forced, CancelOrdersOnExit cancels the open orders of every
+		// authenticated exchange during the cleanup
+		ShutdownTimeout    Duration `json:"shutdown_timeout"`
+		CancelOrdersOnExit bool     `json:"cancel_orders_on_exit"`
+		// QuoteConversions lets books quoted in one asset be compared with
+		// books quoted in another, keyed by the source quote
+		QuoteConversions map[string]QuoteConversion `json:"quote_conversions"`
+	}
+
+	// QuoteConversion turns prices quoted in the source asset into To by
+	// multiplying them with Rate, e.g. USDT to USD at 1
+	QuoteConversion struct {
+		To   string  `json:"to"`
+		Rate float64 `json:"rate"`
+	}
+
+	// Paper holds the starting inventory of the paper trading mode keyed by
+	// exchange name and currency
+	Paper struct {
+		Enable   bool                          `json:"enable"`
+		Balances map[string]map[string]float64 `json:"balances"`
+	}
+
+	// Recorder persists every order book into Dir, relative paths are
+	// resolved against the working directory
+	Recorder struct {
+		Enable bool   `json:"enable"`
+		Dir    string `json:"dir"`
 	}
 
 	Telegram struct {
-		Enable bool   `json: "enable"`
-		ApiKey string `json: "api_key"`
-		ChatId int64  `json: "chat_id"`
-		Debug  bool   `json: "debug"`
+		Enable bool   `json:"enable"`
+		ApiKey string `json:"api_key"`
+		ChatId int64  `json:"chat_id"`
+		Debug  bool   `json:"debug"`
 	}
 
 	Exchange struct {
-		Name                    string `json: "name"`
-		Enabled                 bool   `json: "enabled"`
-		Verbose                 bool   `json: "verbose"`
-		RESTPollingDelay        time.Duration
-		AuthenticatedAPISupport bool   `json: "auth_api_support"`
-		APIKey                  string `json: "api_key"`
-		APISecret               string `json: "api_secret"`
-		ClientID                string `json: "client_id"`
-		Symbol                  string `json: "symbol"`
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+		Verbose bool   `json:"verbose"`
+		// RESTPollingDelay is the least time between two polls of the books
+		// of the exchange, every tick polls them when zero
+		RESTPollingDelay        Duration `json:"rest_polling_delay"`
+		AuthenticatedAPISupport bool     `json:"auth_api_support"`
+		APIKey                  string   `json:"api_key"`
+		APISecret               string   `json:"api_secret"`
+		ClientID                string   `json:"client_id"`
+		EnabledPairs            []string `json:"enabled_pairs"`
+		LotStep                 float64  `json:"lot_step"`
+		Websocket               bool     `json:"websocket"`
+		// the fees in percent override the defaults of the adapter when
+		// present, zero included
+		TakerFee *float64 `json:"taker_fee"`
+		MakerFee *float64 `json:"maker_fee"`
+		// HTTPTimeout bounds every REST request, 15s when zero
+		HTTPTimeout Duration `json:"http_timeout"`
+		// DepthTimeout bounds fetching a single book, the window of the
+		// whole update applies when zero
+		DepthTimeout Duration `json:"depth_timeout"`
+		// the rate limits override the defaults of the adapter
+		PublicRateLimit  RateLimit `json:"public_rate_limit"`
+		PrivateRateLimit RateLimit `json:"private_rate_limit"`
+	}
+
+	// RateLimit allows Rate requests per second on average and bursts of up
+	// to Burst requests
+	RateLimit struct {
+		Rate  float64 `json:"rate"`
+		Burst int     `json:"burst"`
 	}
 )
 
 func Init() {
 	// Try load config from file system
-	err := LoadFile()
+	err := Cfg.LoadConfig(path.Join(CfgDir, CONFIG_FILE))
 	if err != nil {
 		log.Fatal("Error load config file:", "fatal", err.Error())
 	}
 }
 
-func LoadFile() error {
-	defaultPath := path.Join(CfgDir, CONFIG_FILE)
-	file, err := common.ReadFile(defaultPath)
+// GetConfig returns the config shared by the bot
+func GetConfig() *Config {
+	return Cfg
+}
+
+// MaxPollingDelay returns the largest polling delay of the enabled exchanges,
+// their books are kept that long between two polls
+func (c *Config) MaxPollingDelay() time.Duration {
+	var delay time.Duration
+	for _, e := range c.Exchanges {
+		if e.Enabled && e.RESTPollingDelay.Duration > delay {
+			delay = e.RESTPollingDelay.Duration
+		}
+	}
+
+	return delay
+}
+
+// LoadConfig reads the config file strictly, unknown or mistyped fields and
+// invalid values are reported together as a *ValidationError. The config is
+// left untouched when the file has any problem.
+func (c *Config) LoadConfig(file string) error {
+	data, err := common.ReadFile(file)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(file, Cfg)
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%s: %s", file, err)
+	}
+
+	// unknown fields don't keep the values from being checked, values of
+	// the wrong type do
+	problems := checkFields("", raw, reflect.TypeOf(Config{}))
+	loaded := Config{}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		if len(problems) == 0 {
+			return fmt.Errorf("%s: %s", file, err)
+		}
+		return newValidationError(file, problems)
+	}
+
+	problems = append(problems, loaded.Validate()...)
+	if len(problems) > 0 {
+		return newValidationError(file, problems)
+	}
+
+	*c = loaded
+	return nil
 }
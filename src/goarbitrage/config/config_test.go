@@ -0,0 +1,132 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	CONFIG_TEST_FILE = "testdata/config.json"
+)
+
+const invalidConfig = `{
+	"telegram": {"enable": true, "chat_id": "1"},
+	"settings": {"refresh_rate": "10 seconds", "max_tx_volume": 1, "min_tx_volume": 2, "refresh": 5},
+	"exchanges": {
+		"Kraken": {"name": "Kraken", "enabled": true, "RESTPollingDelay": 10, "enabled_pairs": ["BTCUSD"]},
+		"Gemini": {"name": "Bitfinex", "enabled": true, "auth_api_support": true, "taker_fee": -1}
+	}
+}`
+
+func TestLoadConfig(t *testing.T) {
+	c := Config{}
+	if err := c.LoadConfig(CONFIG_TEST_FILE); err != nil {
+		t.Fatalf("Test failed. LoadConfig() error: %s", err)
+	}
+
+	e := c.Exchanges["Bitfinex"]
+	if !e.AuthenticatedAPISupport || e.APIKey != "Key" || e.APISecret != "Secret" || e.RESTPollingDelay.Duration != 750*time.Millisecond {
+		t.Errorf("Test failed. Unexpected exchange: %+v", e)
+	}
+
+	if c.Settings.RefreshRate.Duration != 10*time.Second || c.Settings.DepthTimeout.Duration != 5*time.Second || c.Settings.MaxTxVolume != 1 {
+		t.Errorf("Test failed. Unexpected settings: %+v", c.Settings)
+	}
+}
+
+func TestLoadConfigProblems(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "config.json")
+	if err := ioutil.WriteFile(file, []byte(invalidConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := Config{}
+	err = c.LoadConfig(file)
+	validationErr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Test failed. Expected *ValidationError. Actual %v", err)
+	}
+
+	// the values aren't checked when some have the wrong type
+	expected := []string{
+		"exchanges.Kraken.RESTPollingDelay",
+		"settings.refresh",
+		"settings.refresh_rate",
+		"telegram.chat_id",
+	}
+	if len(validationErr.Problems) != len(expected) {
+		t.Fatalf("Test failed. Expected %d problems. Actual %s", len(expected), err)
+	}
+	for i, p := range validationErr.Problems {
+		if p.Path != expected[i] {
+			t.Errorf("Test failed. Expected problem at %s. Actual %s", expected[i], p)
+		}
+	}
+
+	if c.Exchanges != nil {
+		t.Error("Test failed. Expected the config to be left untouched")
+	}
+
+	valid, err := ioutil.ReadFile(CONFIG_TEST_FILE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknown := strings.Replace(string(valid), `"taker_fee": 0.2`, `"taker_fees": 0.2, "lot_step": -1`, 1)
+	if err := ioutil.WriteFile(file, []byte(unknown), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.LoadConfig(file)
+	if validationErr, ok := err.(*ValidationError); !ok || len(validationErr.Problems) != 2 ||
+		validationErr.Problems[0].Path != "exchanges.Bitfinex.lot_step" || validationErr.Problems[1].Path != "exchanges.Bitfinex.taker_fees" {
+		t.Errorf("Test failed. Expected the unknown field and the invalid lot step. Actual %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	fee := -1.0
+	c := Config{
+		Telegram: Telegram{Enable: true},
+		Settings: Settings{MaxTxVolume: 1, MinTxVolume: 2, MaxBookSkew: Duration{5 * time.Second}},
+		Exchanges: map[string]Exchange{
+			"Kraken":   {Name: "Kraken", Enabled: true, EnabledPairs: []string{"BTCUSD"}, RESTPollingDelay: Duration{10 * time.Second}},
+			"Gemini":   {Name: "Bitfinex", Enabled: true, AuthenticatedAPISupport: true, TakerFee: &fee},
+			"Coinbase": {Enabled: true, EnabledPairs: []string{"BTC/USD"}, AuthenticatedAPISupport: true, APIKey: "Key", APISecret: "Secret"},
+		},
+	}
+
+	expected := []string{
+		"exchanges.Coinbase.client_id",
+		"exchanges.Gemini.api_key",
+		"exchanges.Gemini.api_secret",
+		"exchanges.Gemini.enabled_pairs",
+		"exchanges.Gemini.name",
+		"exchanges.Gemini.taker_fee",
+		"exchanges.Kraken.enabled_pairs[0]",
+		"settings.max_book_skew",
+		"settings.min_tx_volume",
+		"telegram.api_key",
+		"telegram.chat_id",
+	}
+
+	problems := newValidationError("", c.Validate()).Problems
+	if len(problems) != len(expected) {
+		t.Fatalf("Test failed. Expected %d problems. Actual %v", len(expected), problems)
+	}
+	for i, p := range problems {
+		if p.Path != expected[i] {
+			t.Errorf("Test failed. Expected problem at %s. Actual %s", expected[i], p)
+		}
+	}
+}
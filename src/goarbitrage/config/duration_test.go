@@ -0,0 +1,38 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected time.Duration
+	}{
+		{`"750ms"`, 750 * time.Millisecond},
+		{`"1m30s"`, 90 * time.Second},
+		{`10`, 10 * time.Second},
+		{`0.5`, 500 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		var d Duration
+		if err := json.Unmarshal([]byte(test.json), &d); err != nil || d.Duration != test.expected {
+			t.Errorf("Test failed. %s expected %s. Actual %s, %v", test.json, test.expected, d, err)
+		}
+	}
+
+	for _, invalid := range []string{`"10"`, `"ten seconds"`, `true`} {
+		var d Duration
+		if err := json.Unmarshal([]byte(invalid), &d); err == nil {
+			t.Errorf("Test failed. Expected %s to be rejected", invalid)
+		}
+	}
+
+	data, err := json.Marshal(Duration{1500 * time.Millisecond})
+	if err != nil || string(data) != `"1.5s"` {
+		t.Errorf("Test failed. Expected \"1.5s\". Actual %s, %v", data, err)
+	}
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	// Problem is a single issue of a config file at the JSON path of the
+	// offending value, e.g. exchanges.Kraken.api_key
+	Problem struct {
+		Path    string
+		Message string
+	}
+
+	// ValidationError lists every problem found in a config file
+	ValidationError struct {
+		File     string
+		Problems []Problem
+	}
+)
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+	// clientIDExchanges sign requests with the client_id, the Coinbase
+	// passphrase and the Bitstamp customer ID
+	clientIDExchanges = map[string]bool{
+		"Coinbase": true,
+		"Bitstamp": true,
+	}
+)
+
+func (p Problem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+func newValidationError(file string, problems []Problem) *ValidationError {
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+	return &ValidationError{File: file, Problems: problems}
+}
+
+func (e *ValidationError) Error() string {
+	lines := []string{fmt.Sprintf("%s: %d problem(s)", e.File, len(e.Problems))}
+	for _, p := range e.Problems {
+		lines = append(lines, "  "+p.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// checkFields walks the decoded JSON value along the type it is loaded into
+// and reports unknown fields and values of the wrong type. Field names have
+// to match the json tags exactly.
+func checkFields(path string, raw interface{}, t reflect.Type) []Problem {
+	if raw == nil {
+		return nil
+	}
+
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return checkValue(path, raw, t)
+	}
+
+	var problems []Problem
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return []Problem{{path, "expected an object"}}
+		}
+
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+
+		for key, value := range object {
+			field, ok := fields[key]
+			if !ok {
+				problems = append(problems, Problem{joinPath(path, key), "unknown field"})
+				continue
+			}
+			problems = append(problems, checkFields(joinPath(path, key), value, field)...)
+		}
+	case reflect.Map:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return []Problem{{path, "expected an object"}}
+		}
+
+		for key, value := range object {
+			problems = append(problems, checkFields(joinPath(path, key), value, t.Elem())...)
+		}
+	case reflect.Slice:
+		array, ok := raw.([]interface{})
+		if !ok {
+			return []Problem{{path, "expected an array"}}
+		}
+
+		for i, value := range array {
+			problems = append(problems, checkFields(fmt.Sprintf("%s[%d]", path, i), value, t.Elem())...)
+		}
+	default:
+		return checkValue(path, raw, t)
+	}
+
+	return problems
+}
+
+// checkValue decodes a leaf value on its own so that its error can be
+// reported at its path
+func checkValue(path string, raw interface{}, t reflect.Type) []Problem {
+	data, err := json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(data, reflect.New(t).Interface())
+	}
+
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+		return []Problem{{path, fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value)}}
+	}
+	if err != nil {
+		return []Problem{{path, err.Error()}}
+	}
+
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// Validate checks the ranges of the values and the fields required by the
+// enabled features
+func (c *Config) Validate() []Problem {
+	var problems []Problem
+	add := func(path, format string, args ...interface{}) {
+		problems = append(problems, Problem{path, fmt.Sprintf(format, args...)})
+	}
+
+	s := c.Settings
+	durations := map[string]Duration{
+		"refresh_rate":     s.RefreshRate,
+		"depth_timeout":    s.DepthTimeout,
+		"max_book_age":     s.MaxBookAge,
+		"max_book_skew":    s.MaxBookSkew,
+		"shutdown_timeout": s.ShutdownTimeout,
+	}
+	for key, d := range durations {
+		if d.Duration < 0 {
+			add("settings."+key, "must not be negative")
+		}
+	}
+
+	// polled books are kept between two polls, they must not be evicted or
+	// skipped in the meantime
+	if delay := c.MaxPollingDelay(); delay > 0 {
+		if s.MaxBookAge.Duration > 0 && s.MaxBookAge.Duration <= delay {
+			add("settings.max_book_age", "must exceed the largest rest_polling_delay %s", delay)
+		}
+		if s.MaxBookSkew.Duration > 0 && s.MaxBookSkew.Duration <= delay {
+			add("settings.max_book_skew", "must exceed the largest rest_polling_delay %s", delay)
+		}
+	}
+
+	if s.MaxTxVolume <= 0 {
+		add("settings.max_tx_volume", "must be positive")
+	}
+	if s.MinTxVolume < 0 {
+		add("settings.min_tx_volume", "must not be negative")
+	} else if s.MinTxVolume > s.MaxTxVolume {
+		add("settings.min_tx_volume", "must not exceed max_tx_volume %v", s.MaxTxVolume)
+	}
+	if s.ProfitThresh < 0 {
+		add("settings.profit_thresh", "must not be negative")
+	}
+	if s.PercThresh < 0 {
+		add("settings.perc_thresh", "must not be negative")
+	}
+	if s.ArbitrageBuyQueue < 0 {
+		add("settings.arbitrage_buy_queue", "must not be negative")
+	}
+	if s.ArbitrageSellQueue < 0 {
+		add("settings.arbitrage_sell_queue", "must not be negative")
+	}
+
+	for source, conversion := range s.QuoteConversions {
+		path := "settings.quote_conversions." + source
+		if conversion.To == "" {
+			add(path+".to", "is required")
+		}
+		if conversion.Rate <= 0 {
+			add(path+".rate", "must be positive")
+		}
+	}
+
+	if c.Telegram.Enable {
+		if c.Telegram.ApiKey == "" {
+			add("telegram.api_key", "is required when telegram is enabled")
+		}
+		if c.Telegram.ChatId == 0 {
+			add("telegram.chat_id", "is required when telegram is enabled")
+		}
+	}
+
+	if c.Paper.Enable {
+		for name, balances := range c.Paper.Balances {
+			for currency, amount := range balances {
+				if amount < 0 {
+					add("paper.balances."+name+"."+currency, "must not be negative")
+				}
+			}
+		}
+	}
+
+	if c.Recorder.Enable && c.Recorder.Dir == "" {
+		add("recorder.dir", "is required when the recorder is enabled")
+	}
+
+	if len(c.Exchanges) == 0 {
+		add("exchanges", "is required")
+	}
+
+	for name, e := range c.Exchanges {
+		problems = append(problems, e.validate("exchanges."+name, name)...)
+	}
+
+	return problems
+}
+
+func (e Exchange) validate(path, name string) []Problem {
+	var problems []Problem
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, Problem{joinPath(path, key), fmt.Sprintf(format, args...)})
+	}
+
+	if e.Name != "" && e.Name != name {
+		add("name", "must match the key %q", name)
+	}
+
+	durations := map[string]Duration{
+		"rest_polling_delay": e.RESTPollingDelay,
+		"http_timeout":       e.HTTPTimeout,
+		"depth_timeout":      e.DepthTimeout,
+	}
+	for key, d := range durations {
+		if d.Duration < 0 {
+			add(key, "must not be negative")
+		}
+	}
+
+	if e.TakerFee != nil && *e.TakerFee < 0 {
+		add("taker_fee", "must not be negative")
+	}
+	if e.MakerFee != nil && *e.MakerFee < 0 {
+		add("maker_fee", "must not be negative")
+	}
+	if e.LotStep < 0 {
+		add("lot_step", "must not be negative")
+	}
+
+	limits := map[string]RateLimit{
+		"public_rate_limit":  e.PublicRateLimit,
+		"private_rate_limit": e.PrivateRateLimit,
+	}
+	for key, limit := range limits {
+		if limit.Rate < 0 {
+			add(key+".rate", "must not be negative")
+		}
+		if limit.Burst < 0 {
+			add(key+".burst", "must not be negative")
+		}
+	}
+
+	if !e.Enabled {
+		return problems
+	}
+
+	if len(e.EnabledPairs) == 0 {
+		add("enabled_pairs", "needs at least one pair when the exchange is enabled")
+	}
+	for i, pair := range e.EnabledPairs {
+		parts := strings.Split(pair, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			add(fmt.Sprintf("enabled_pairs[%d]", i), "invalid pair %q, expected BASE/QUOTE", pair)
+		}
+	}
+
+	if e.AuthenticatedAPISupport {
+		if e.APIKey == "" {
+			add("api_key", "is required when auth_api_support is on")
+		}
+		if e.APISecret == "" {
+			add("api_secret", "is required when auth_api_support is on")
+		}
+		if e.ClientID == "" && clientIDExchanges[name] {
+			add("client_id", "is required by %s when auth_api_support is on", name)
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,249 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/config"
+	"goarbitrage/exchanges"
+)
+
+const (
+	BINANCE_API_URL     = "https://api.binance.com"
+	BINANCE_API_VERSION = "3"
+
+	BINANCE_DEPTH         = "depth"
+	BINANCE_EXCHANGE_INFO = "exchangeInfo"
+	BINANCE_ACCOUNT       = "account"
+	BINANCE_ORDER         = "order"
+	BINANCE_OPEN_ORDERS   = "openOrders"
+	BINANCE_DEPTH_LIMIT   = 20
+	BINANCE_RECV_WINDOW   = 5000
+	BINANCE_SYMBOL_ACTIVE = "TRADING"
+)
+
+var (
+	// Binance spells symbols in upper case without delimiter, BTCUSDT. It
+	// has no USD books, dollar pairs are quoted in the USDT token.
+	quoteAssets = []string{"USDT", "BTC", "ETH", "BNB"}
+
+	pairFormat = exchange.PairFormat{
+		Uppercase: true,
+		Quotes:    quoteAssets,
+	}
+)
+
+type Binance struct {
+	exchange.ExchangeBase
+}
+
+func init() {
+	exchange.Register("Binance", func() exchange.IBotExchange {
+		return new(Binance)
+	})
+}
+
+func (b *Binance) SetDefaults() {
+	b.Name = "Binance"
+	b.Enabled = false
+	b.Verbose = false
+	b.TakerFee = 0.1
+	b.MakerFee = 0.1
+	b.LotStep = 0.000001
+	b.PairFormat = pairFormat
+	b.QuoteAssets = quoteAssets
+	b.APIUrl = BINANCE_API_URL
+	b.SetRateLimits(config.RateLimit{Rate: 10, Burst: 20}, config.RateLimit{Rate: 5, Burst: 10})
+}
+
+func (b *Binance) Setup(exch config.Exchange) {
+	if !exch.Enabled {
+		b.SetEnabled(false)
+		return
+	}
+
+	b.Enabled = true
+	b.AuthenticatedAPISupport = exch.AuthenticatedAPISupport
+	b.SetAPIKeys(exch.APIKey, exch.APISecret, "", false)
+	b.RESTPollingDelay = exch.RESTPollingDelay.Duration
+	b.Verbose = exch.Verbose
+	b.SetEnabledPairs(exch.EnabledPairs)
+	b.SetFees(exch.TakerFee, exch.MakerFee)
+	b.SetHTTPTimeout(exch.HTTPTimeout.Duration)
+	b.SetRateLimits(exch.PublicRateLimit, exch.PrivateRateLimit)
+	b.DepthTimeout = exch.DepthTimeout.Duration
+	if exch.LotStep > 0 {
+		b.LotStep = exch.LotStep
+	}
+}
+
+func (b *Binance) GetOrderBook(ctx context.Context, symbol string, limit int) (BinanceOrderBook, error) {
+	values := url.Values{}
+	values.Set("symbol", symbol)
+	values.Set("limit", strconv.Itoa(limit))
+
+	response := BinanceOrderBook{}
+	err := b.SendHTTPGetRequest(ctx, BINANCE_DEPTH, values, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (b *Binance) GetSymbols() ([]exchange.CurrencyPair, error) {
+	response := BinanceExchangeInfo{}
+	err := b.SendHTTPGetRequest(context.Background(), BINANCE_EXCHANGE_INFO, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []exchange.CurrencyPair{}
+	for _, i := range response.Symbols {
+		if i.Status != BINANCE_SYMBOL_ACTIVE {
+			continue
+		}
+		pairs = append(pairs, exchange.NewCurrencyPair(i.BaseAsset, i.QuoteAsset))
+	}
+	return pairs, nil
+}
+
+func (b *Binance) GetAccount(ctx context.Context) (BinanceAccount, error) {
+	response := BinanceAccount{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, "GET", BINANCE_ACCOUNT, nil, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// NewOrder places a good till cancelled limit order or a market order when
+// the price is zero
+func (b *Binance) NewOrder(ctx context.Context, symbol, side string, quantity, price float64) (BinanceOrder, error) {
+	values := url.Values{}
+	values.Set("symbol", symbol)
+	values.Set("side", side)
+	values.Set("quantity", strconv.FormatFloat(quantity, 'f', -1, 64))
+	values.Set("newOrderRespType", "RESULT")
+
+	if price > 0 {
+		values.Set("type", "LIMIT")
+		values.Set("timeInForce", "GTC")
+		values.Set("price", strconv.FormatFloat(price, 'f', -1, 64))
+	} else {
+		values.Set("type", "MARKET")
+	}
+
+	response := BinanceOrder{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, "POST", BINANCE_ORDER, values, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (b *Binance) CancelOrder(ctx context.Context, symbol string, orderID int64) (BinanceOrder, error) {
+	return b.orderRequest(ctx, "DELETE", symbol, orderID)
+}
+
+func (b *Binance) GetOrder(ctx context.Context, symbol string, orderID int64) (BinanceOrder, error) {
+	return b.orderRequest(ctx, "GET", symbol, orderID)
+}
+
+// GetOpenOrdersAll lists the open orders of every symbol
+func (b *Binance) GetOpenOrdersAll(ctx context.Context) ([]BinanceOrder, error) {
+	response := []BinanceOrder{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, "GET", BINANCE_OPEN_ORDERS, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (b *Binance) orderRequest(ctx context.Context, method, symbol string, orderID int64) (BinanceOrder, error) {
+	values := url.Values{}
+	values.Set("symbol", symbol)
+	values.Set("orderId", strconv.FormatInt(orderID, 10))
+
+	response := BinanceOrder{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, method, BINANCE_ORDER, values, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (b *Binance) SendHTTPGetRequest(ctx context.Context, method string, values url.Values, result interface{}) error {
+	path := common.EncodeURLValues(fmt.Sprintf("%s/api/v%s/%s", b.APIUrl, BINANCE_API_VERSION, method), values)
+
+	err := b.SendPublicRequest(ctx, path, result)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SendAuthenticatedHTTPRequest appends timestamp and recvWindow to the query
+// string and signs it with the hex HMAC-SHA256 of the secret
+func (b *Binance) SendAuthenticatedHTTPRequest(ctx context.Context, method, path string, values url.Values, result interface{}) error {
+	if len(b.APIKey) == 0 {
+		return errors.New("SendAuthenticatedHTTPRequest: Invalid API key")
+	}
+
+	if err := b.WaitRateLimit(ctx, exchange.ENDPOINT_PRIVATE); err != nil {
+		return err
+	}
+
+	if values == nil {
+		values = url.Values{}
+	}
+
+	values.Set("timestamp", strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10))
+	values.Set("recvWindow", strconv.Itoa(BINANCE_RECV_WINDOW))
+
+	query := values.Encode()
+	if b.Verbose {
+		log.Info("Request:", "info", method+" "+path+"?"+query)
+	}
+
+	hmac := common.GetHMAC(common.HASH_SHA256, []byte(query), []byte(b.APISecret))
+	query += "&signature=" + common.HexEncodeToString(hmac)
+
+	headers := make(map[string]string)
+	headers["X-MBX-APIKEY"] = b.APIKey
+
+	endpoint := fmt.Sprintf("%s/api/v%s/%s?%s", b.APIUrl, BINANCE_API_VERSION, path, query)
+	resp, err := b.GetHTTPClient().SendRequest(ctx, method, endpoint, headers, strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+
+	if b.Verbose {
+		log.Info("Recieved raw:", "info", resp)
+	}
+
+	errResponse := BinanceErrorResponse{}
+	if common.JSONDecode([]byte(resp), &errResponse) == nil && errResponse.Code != 0 {
+		return fmt.Errorf("SendAuthenticatedHTTPRequest: %d: %s", errResponse.Code, errResponse.Msg)
+	}
+
+	err = common.JSONDecode([]byte(resp), &result)
+	if err != nil {
+		return errors.New("Unable to JSON Unmarshal response.")
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+
+	"goarbitrage/config"
+	"goarbitrage/exchanges"
+	"goarbitrage/exchanges/exchangetest"
+)
+
+// verify rejects keyed requests whose query isn't signed with "secret"
+func verify(r *http.Request, body []byte) *exchangetest.Response {
+	if r.Header.Get("X-MBX-APIKEY") == "" {
+		return nil
+	}
+
+	query := r.URL.RawQuery
+	i := strings.LastIndex(query, "&signature=")
+	if i < 0 {
+		return &exchangetest.Response{Status: http.StatusBadRequest, Body: `{"code":-1102,"msg":"Mandatory parameter 'signature' was not sent."}`}
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(query[:i]))
+
+	q := r.URL.Query()
+	if query[i+len("&signature="):] != hex.EncodeToString(mac.Sum(nil)) || q.Get("timestamp") == "" || q.Get("recvWindow") == "" {
+		return &exchangetest.Response{Status: http.StatusUnauthorized, Body: `{"code":-1022,"msg":"Signature for this request is not valid."}`}
+	}
+
+	return nil
+}
+
+func newTestBinance(t *testing.T, routes map[string]string) (*Binance, *exchangetest.Server) {
+	server := exchangetest.NewServer(t, verify, routes)
+
+	b := &Binance{}
+	b.SetDefaults()
+	server.Attach(&b.ExchangeBase)
+	b.EnabledPairs = []string{"BTC/USDT"}
+	b.SetAPIKeys("key", "secret", "", false)
+	return b, server
+}
+
+func TestUpdateDepth(t *testing.T) {
+	b, server := newTestBinance(t, map[string]string{
+		"GET /api/v3/depth": `{"lastUpdateId":1027024,"bids":[["4000.00000000","431.00000000"]],"asks":[["4000.00000200","12.00000000"]]}`,
+	})
+	defer server.Close()
+
+	book, err := b.UpdateDepth(context.Background(), exchange.NewCurrencyPair("BTC", "USDT"))
+	if err != nil {
+		t.Fatalf("Test failed. UpdateDepth() error: %s", err)
+	}
+
+	if len(book.Bids) != 1 || len(book.Asks) != 1 {
+		t.Fatalf("Test failed. Unexpected book: %+v", book)
+	}
+
+	if book.Bids[0].Price != 4000 || book.Bids[0].Amount != 431 || book.Asks[0].Price != 4000.000002 {
+		t.Errorf("Test failed. Unexpected book: %+v", book)
+	}
+}
+
+func TestSignedRequests(t *testing.T) {
+	b, server := newTestBinance(t, map[string]string{
+		"GET /api/v3/account": `{"canTrade":true,"balances":[{"asset":"BTC","free":"4723846.89208129","locked":"0.00000000"},
+			{"asset":"USDT","free":"1000.5","locked":"10"}]}`,
+		"POST /api/v3/order": `{"symbol":"BTCUSDT","orderId":28,"clientOrderId":"6gCrw2kRUAF9CvJDGP16IP","price":"4000.00",
+			"origQty":"1.00","executedQty":"0.25","cummulativeQuoteQty":"1000.00","status":"PARTIALLY_FILLED","type":"LIMIT","side":"BUY"}`,
+	})
+	defer server.Close()
+
+	balances, err := b.GetBalances(context.Background())
+	if err != nil {
+		t.Fatalf("Test failed. GetBalances() error: %s", err)
+	}
+
+	if balances["USDT"].Available != 1000.5 || balances["USDT"].Total != 1010.5 {
+		t.Errorf("Test failed. Unexpected balances: %+v", balances)
+	}
+
+	order, err := b.SubmitExchangeOrder(context.Background(), exchange.NewCurrencyPair("BTC", "USDT"), exchange.SideBuy, exchange.OrderTypeLimit, 1, 4000)
+	if err != nil {
+		t.Fatalf("Test failed. SubmitExchangeOrder() error: %s", err)
+	}
+
+	if order.ID != "28" || order.Symbol != "BTC/USDT" || order.Side != exchange.SideBuy || order.AvgPrice != 4000 {
+		t.Errorf("Test failed. Unexpected order: %+v", order)
+	}
+
+	if order.Status != exchange.OrderStatusPartiallyFilled {
+		t.Errorf("Test failed. Expected %s. Actual %s", exchange.OrderStatusPartiallyFilled, order.Status)
+	}
+
+	b.APISecret = "wrong"
+	if _, err := b.GetBalances(context.Background()); err == nil || !strings.Contains(err.Error(), "-1022") {
+		t.Errorf("Test failed. Expected signature error. Actual %v", err)
+	}
+}
+
+func TestQuoteAssets(t *testing.T) {
+	b := Binance{}
+	b.SetDefaults()
+	b.Setup(config.Exchange{Enabled: true, EnabledPairs: []string{"BTC/USD", "BTC/USDT", "ETH/BTC"}})
+
+	pairs := b.GetEnabledPairs()
+	if len(pairs) != 2 || pairs[0].String() != "BTC/USDT" || pairs[1].String() != "ETH/BTC" {
+		t.Errorf("Test failed. Expected USD pair to be dropped. Actual %v", pairs)
+	}
+
+	if symbol := b.FormatSymbol(exchange.NewCurrencyPair("BTC", "USDT")); symbol != "BTCUSDT" {
+		t.Errorf("Test failed. Expected BTCUSDT. Actual %s", symbol)
+	}
+}
@@ -0,0 +1,83 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+type (
+	// BinanceBookEntry is a [price, quantity] array of strings
+	BinanceBookEntry struct {
+		Price  float64
+		Amount float64
+	}
+
+	BinanceOrderBook struct {
+		LastUpdateID int64              `json:"lastUpdateId"`
+		Bids         []BinanceBookEntry `json:"bids"`
+		Asks         []BinanceBookEntry `json:"asks"`
+	}
+
+	BinanceErrorResponse struct {
+		Code int64  `json:"code"`
+		Msg  string `json:"msg"`
+	}
+
+	BinanceSymbol struct {
+		Symbol     string `json:"symbol"`
+		Status     string `json:"status"`
+		BaseAsset  string `json:"baseAsset"`
+		QuoteAsset string `json:"quoteAsset"`
+	}
+
+	BinanceExchangeInfo struct {
+		Symbols []BinanceSymbol `json:"symbols"`
+	}
+
+	BinanceBalance struct {
+		Asset  string  `json:"asset"`
+		Free   float64 `json:"free,string"`
+		Locked float64 `json:"locked,string"`
+	}
+
+	BinanceAccount struct {
+		CanTrade bool             `json:"canTrade"`
+		Balances []BinanceBalance `json:"balances"`
+	}
+
+	BinanceOrder struct {
+		Symbol              string  `json:"symbol"`
+		OrderID             int64   `json:"orderId"`
+		ClientOrderID       string  `json:"clientOrderId"`
+		Price               float64 `json:"price,string"`
+		OrigQty             float64 `json:"origQty,string"`
+		ExecutedQty         float64 `json:"executedQty,string"`
+		CummulativeQuoteQty float64 `json:"cummulativeQuoteQty,string"`
+		Status              string  `json:"status"`
+		TimeInForce         string  `json:"timeInForce"`
+		Type                string  `json:"type"`
+		Side                string  `json:"side"`
+	}
+)
+
+func (e *BinanceBookEntry) UnmarshalJSON(data []byte) error {
+	var entry []string
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+
+	if len(entry) < 2 {
+		return errors.New("Binance book entry: expected [price, quantity]")
+	}
+
+	var err error
+	if e.Price, err = strconv.ParseFloat(entry[0], 64); err != nil {
+		return err
+	}
+	if e.Amount, err = strconv.ParseFloat(entry[1], 64); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+)
+
+func (b *Binance) UpdateDepth(ctx context.Context, pair exchange.CurrencyPair) (exchange.OrderBook, error) {
+	symbol := b.FormatSymbol(pair)
+	if b.Verbose {
+		log.Info(fmt.Sprintf("%s polling %s", b.GetName(), symbol))
+	}
+
+	book, err := b.GetOrderBook(ctx, symbol, BINANCE_DEPTH_LIMIT)
+	if err != nil {
+		return exchange.OrderBook{}, err
+	}
+
+	var t exchange.OrderBook
+	for _, i := range book.Bids {
+		t.Bids = append(t.Bids, exchange.ItemBook{Price: i.Price, Amount: i.Amount})
+	}
+	for _, i := range book.Asks {
+		t.Asks = append(t.Asks, exchange.ItemBook{Price: i.Price, Amount: i.Amount})
+	}
+
+	return t, nil
+}
+
+func (b *Binance) SubmitExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, side exchange.OrderSide, orderType exchange.OrderType, amount, price float64) (exchange.Order, error) {
+	switch orderType {
+	case exchange.OrderTypeLimit:
+		if price <= 0 {
+			return exchange.Order{}, fmt.Errorf("%s: limit order without price", b.Name)
+		}
+	case exchange.OrderTypeMarket:
+		price = 0
+	default:
+		return exchange.Order{}, fmt.Errorf("%s: unsupported order type %s", b.Name, orderType)
+	}
+
+	order, err := b.NewOrder(ctx, b.FormatSymbol(pair), common.StringToUpper(string(side)), amount, price)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromBinance(order), nil
+}
+
+func (b *Binance) CancelExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("%s: invalid order id %s", b.Name, orderID)
+	}
+
+	order, err := b.CancelOrder(ctx, b.FormatSymbol(pair), id)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromBinance(order), nil
+}
+
+func (b *Binance) GetExchangeOrderInfo(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("%s: invalid order id %s", b.Name, orderID)
+	}
+
+	order, err := b.GetOrder(ctx, b.FormatSymbol(pair), id)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromBinance(order), nil
+}
+
+// GetOpenOrders returns the open orders of every symbol of the account
+func (b *Binance) GetOpenOrders(ctx context.Context) ([]exchange.Order, error) {
+	response, err := b.GetOpenOrdersAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []exchange.Order
+	for _, o := range response {
+		orders = append(orders, orderFromBinance(o))
+	}
+
+	return orders, nil
+}
+
+func (b *Binance) GetBalances(ctx context.Context) (map[string]exchange.Balance, error) {
+	account, err := b.GetAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]exchange.Balance{}
+	for _, i := range account.Balances {
+		currency := common.StringToUpper(i.Asset)
+		result[currency] = exchange.Balance{
+			Currency:  currency,
+			Available: i.Free,
+			Total:     i.Free + i.Locked,
+		}
+	}
+
+	return result, nil
+}
+
+func orderFromBinance(o BinanceOrder) exchange.Order {
+	orderType := exchange.OrderTypeLimit
+	if o.Type == "MARKET" {
+		orderType = exchange.OrderTypeMarket
+	}
+
+	symbol := o.Symbol
+	if pair, err := pairFormat.Parse(o.Symbol); err == nil {
+		symbol = pair.String()
+	}
+
+	var avgPrice float64
+	if o.ExecutedQty > 0 {
+		avgPrice = o.CummulativeQuoteQty / o.ExecutedQty
+	}
+
+	isLive := o.Status == "NEW" || o.Status == "PARTIALLY_FILLED"
+	isCancelled := o.Status == "CANCELED" || o.Status == "REJECTED" || o.Status == "EXPIRED"
+
+	return exchange.Order{
+		ID:           strconv.FormatInt(o.OrderID, 10),
+		Symbol:       symbol,
+		Side:         exchange.OrderSide(common.StringToLower(o.Side)),
+		Type:         orderType,
+		Price:        o.Price,
+		Amount:       o.OrigQty,
+		FilledAmount: o.ExecutedQty,
+		AvgPrice:     avgPrice,
+		Status:       exchange.OrderStatusFromState(isLive, isCancelled, o.OrigQty, o.ExecutedQty),
+	}
+}
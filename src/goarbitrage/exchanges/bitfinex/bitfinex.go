@@ -1,6 +1,7 @@
 package bitfinex
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -19,21 +20,42 @@ const (
 	BITFINEX_API_URL      = "https://api.bitfinex.com/v1/"
 	BITFINEX_API_VERSION  = "1"
 	BITFINEX_ORDERBOOK    = "book/"
+	BITFINEX_ORDERS       = "orders"
 	BITFINEX_ORDER_NEW    = "order/new"
 	BITFINEX_ORDER_CANCEL = "order/cancel"
 	BITFINEX_ORDER_STATUS = "order/status"
 	BITFINEX_SYMBOLS      = "symbols/"
+	BITFINEX_BALANCES     = "balances"
+)
+
+var (
+	// Bitfinex v1 spells symbols in lower case without delimiter, btcusd
+	pairFormat = exchange.PairFormat{
+		Quotes: []string{"USD", "EUR", "GBP", "JPY", "BTC", "ETH", "UST"},
+	}
 )
 
 type Bitfinex struct {
 	exchange.ExchangeBase
+	Websocket *BitfinexWebsocket
+}
+
+func init() {
+	exchange.Register("Bitfinex", func() exchange.IBotExchange {
+		return new(Bitfinex)
+	})
 }
 
 func (b *Bitfinex) SetDefaults() {
 	b.Name = "Bitfinex"
 	b.Enabled = false
 	b.Verbose = false
-	b.RESTPollingDelay = 10
+	b.TakerFee = 0.2
+	b.MakerFee = 0.1
+	b.LotStep = 0.00000001
+	b.PairFormat = pairFormat
+	b.APIUrl = BITFINEX_API_URL
+	b.SetRateLimits(config.RateLimit{Rate: 1, Burst: 10}, config.RateLimit{Rate: 1, Burst: 5})
 }
 
 func (b *Bitfinex) Setup(exch config.Exchange) {
@@ -45,16 +67,44 @@ func (b *Bitfinex) Setup(exch config.Exchange) {
 	b.Enabled = true
 	b.AuthenticatedAPISupport = exch.AuthenticatedAPISupport
 	b.SetAPIKeys(exch.APIKey, exch.APISecret, "", false)
-	b.RESTPollingDelay = exch.RESTPollingDelay
+	b.RESTPollingDelay = exch.RESTPollingDelay.Duration
 	b.Verbose = exch.Verbose
-	b.Symbol = exch.Symbol
+	b.SetEnabledPairs(exch.EnabledPairs)
+	b.SetFees(exch.TakerFee, exch.MakerFee)
+	b.SetHTTPTimeout(exch.HTTPTimeout.Duration)
+	b.SetRateLimits(exch.PublicRateLimit, exch.PrivateRateLimit)
+	b.DepthTimeout = exch.DepthTimeout.Duration
+	if exch.LotStep > 0 {
+		b.LotStep = exch.LotStep
+	}
+
+	if exch.Websocket {
+		var symbols []string
+		for _, pair := range b.GetEnabledPairs() {
+			symbols = append(symbols, b.FormatSymbol(pair))
+		}
+
+		b.Websocket = NewBitfinexWebsocket(BITFINEX_WEBSOCKET_URL, symbols)
+		b.Websocket.Verbose = b.Verbose
+		b.Websocket.Start()
+	}
 }
 
-func (b *Bitfinex) GetOrderBook(symbol string, values url.Values) (BitfinexOrderBook, error) {
+// Close stops the websocket feed, books are polled over REST afterwards
+func (b *Bitfinex) Close() error {
+	if b.Websocket != nil {
+		b.Websocket.Close()
+		b.Websocket = nil
+	}
+
+	return nil
+}
+
+func (b *Bitfinex) GetOrderBook(ctx context.Context, symbol string, values url.Values) (BitfinexOrderBook, error) {
 	var response BitfinexOrderBook
-	path := common.EncodeURLValues(BITFINEX_API_URL+BITFINEX_ORDERBOOK+symbol, values)
+	path := common.EncodeURLValues(b.APIUrl+BITFINEX_ORDERBOOK+symbol, values)
 
-	err := common.SendHTTPGetRequest(path, true, &response)
+	err := b.SendPublicRequest(ctx, path, &response)
 	if err != nil {
 		return response, err
 	}
@@ -62,7 +112,7 @@ func (b *Bitfinex) GetOrderBook(symbol string, values url.Values) (BitfinexOrder
 	return response, nil
 }
 
-func (b *Bitfinex) NewOrder(Symbol string, Amount float64, Price float64, Buy bool, Type string, Hidden bool) (BitfinexOrder, error) {
+func (b *Bitfinex) NewOrder(ctx context.Context, Symbol string, Amount float64, Price float64, Buy bool, Type string, Hidden bool) (BitfinexOrder, error) {
 	request := make(map[string]interface{})
 	request["symbol"] = Symbol
 	request["amount"] = strconv.FormatFloat(Amount, 'f', -1, 64)
@@ -76,7 +126,7 @@ func (b *Bitfinex) NewOrder(Symbol string, Amount float64, Price float64, Buy bo
 	}
 
 	response := BitfinexOrder{}
-	err := b.SendAuthenticatedHTTPRequest("POST", BITFINEX_ORDER_NEW, request, &response)
+	err := b.SendAuthenticatedHTTPRequest(ctx, "POST", BITFINEX_ORDER_NEW, request, &response)
 	if err != nil {
 		return response, err
 	}
@@ -84,12 +134,12 @@ func (b *Bitfinex) NewOrder(Symbol string, Amount float64, Price float64, Buy bo
 	return response, nil
 }
 
-func (b *Bitfinex) CancelOrder(OrderID int64) (BitfinexOrder, error) {
+func (b *Bitfinex) CancelOrder(ctx context.Context, OrderID int64) (BitfinexOrder, error) {
 	request := make(map[string]interface{})
 	request["order_id"] = OrderID
 	response := BitfinexOrder{}
 
-	err := b.SendAuthenticatedHTTPRequest("POST", BITFINEX_ORDER_CANCEL, request, &response)
+	err := b.SendAuthenticatedHTTPRequest(ctx, "POST", BITFINEX_ORDER_CANCEL, request, &response)
 	if err != nil {
 		return response, err
 	}
@@ -97,12 +147,12 @@ func (b *Bitfinex) CancelOrder(OrderID int64) (BitfinexOrder, error) {
 	return response, nil
 }
 
-func (b *Bitfinex) GetOrderStatus(OrderID int64) (BitfinexOrder, error) {
+func (b *Bitfinex) GetOrderStatus(ctx context.Context, OrderID int64) (BitfinexOrder, error) {
 	request := make(map[string]interface{})
 	request["order_id"] = OrderID
 	orderStatus := BitfinexOrder{}
 
-	err := b.SendAuthenticatedHTTPRequest("POST", BITFINEX_ORDER_STATUS, request, &orderStatus)
+	err := b.SendAuthenticatedHTTPRequest(ctx, "POST", BITFINEX_ORDER_STATUS, request, &orderStatus)
 	if err != nil {
 		return orderStatus, err
 	}
@@ -110,20 +160,54 @@ func (b *Bitfinex) GetOrderStatus(OrderID int64) (BitfinexOrder, error) {
 	return orderStatus, err
 }
 
-func (b *Bitfinex) GetSymbols() ([]string, error) {
+func (b *Bitfinex) GetOrders(ctx context.Context) ([]BitfinexOrder, error) {
+	response := []BitfinexOrder{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, "POST", BITFINEX_ORDERS, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (b *Bitfinex) GetAccountBalances(ctx context.Context) ([]BitfinexBalance, error) {
+	response := []BitfinexBalance{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, "POST", BITFINEX_BALANCES, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (b *Bitfinex) GetSymbols() ([]exchange.CurrencyPair, error) {
 	products := []string{}
-	err := common.SendHTTPGetRequest(BITFINEX_API_URL+BITFINEX_SYMBOLS, true, &products)
+	err := b.SendPublicRequest(context.Background(), b.APIUrl+BITFINEX_SYMBOLS, &products)
 	if err != nil {
 		return nil, err
 	}
-	return products, nil
+
+	pairs := []exchange.CurrencyPair{}
+	for _, i := range products {
+		pair, err := b.ParseSymbol(i)
+		if err != nil {
+			log.Warn("Bitfinex symbol skipped:", "warn", err.Error())
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
 }
 
-func (b *Bitfinex) SendAuthenticatedHTTPRequest(method, path string, params map[string]interface{}, result interface{}) error {
+func (b *Bitfinex) SendAuthenticatedHTTPRequest(ctx context.Context, method, path string, params map[string]interface{}, result interface{}) error {
 	if len(b.APIKey) == 0 {
 		return errors.New("SendAuthenticatedHTTPRequest: Invalid API key")
 	}
 
+	if err := b.WaitRateLimit(ctx, exchange.ENDPOINT_PRIVATE); err != nil {
+		return err
+	}
+
 	request := make(map[string]interface{})
 	request["request"] = fmt.Sprintf("/v%s/%s", BITFINEX_API_VERSION, path)
 	request["nonce"] = strconv.FormatInt(time.Now().UnixNano(), 10)
@@ -150,7 +234,7 @@ func (b *Bitfinex) SendAuthenticatedHTTPRequest(method, path string, params map[
 	headers["X-BFX-PAYLOAD"] = PayloadBase64
 	headers["X-BFX-SIGNATURE"] = common.HexEncodeToString(hmac)
 
-	resp, err := common.SendHTTPRequest(method, BITFINEX_API_URL+path, headers, strings.NewReader(""))
+	resp, err := b.GetHTTPClient().SendRequest(ctx, method, b.APIUrl+path, headers, strings.NewReader(""))
 	if err != nil {
 		return err
 	}
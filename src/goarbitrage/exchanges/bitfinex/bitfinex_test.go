@@ -0,0 +1,60 @@
+package bitfinex
+
+import (
+	"testing"
+
+	"goarbitrage/exchanges"
+)
+
+func TestOrderFromBitfinex(t *testing.T) {
+	order := orderFromBitfinex(BitfinexOrder{
+		OrderID:               448364249,
+		Symbol:                "btcusd",
+		Price:                 0.01,
+		AverageExecutionPrice: 0.01,
+		Side:                  "buy",
+		Type:                  "exchange limit",
+		IsLive:                true,
+		OriginalAmount:        0.02,
+		RemainingAmount:       0.01,
+		ExecutedAmount:        0.01,
+	})
+
+	if order.ID != "448364249" || order.Symbol != "BTC/USD" {
+		t.Errorf("Test failed. Unexpected id or symbol: %+v", order)
+	}
+
+	if order.Side != exchange.SideBuy || order.Type != exchange.OrderTypeLimit {
+		t.Errorf("Test failed. Unexpected side or type: %+v", order)
+	}
+
+	if order.Status != exchange.OrderStatusPartiallyFilled || order.FilledAmount != 0.01 || order.Amount != 0.02 {
+		t.Errorf("Test failed. Unexpected fill state: %+v", order)
+	}
+
+	order = orderFromBitfinex(BitfinexOrder{
+		ID:             1,
+		Type:           "exchange market",
+		OriginalAmount: 1,
+		ExecutedAmount: 1,
+	})
+	if order.Type != exchange.OrderTypeMarket || order.Status != exchange.OrderStatusFilled {
+		t.Errorf("Test failed. Unexpected market order mapping: %+v", order)
+	}
+}
+
+func TestBalancesFromBitfinex(t *testing.T) {
+	balances := balancesFromBitfinex([]BitfinexBalance{
+		{Type: "deposit", Currency: "btc", Amount: 5, Available: 5},
+		{Type: "exchange", Currency: "btc", Amount: 1.5, Available: 1},
+		{Type: "exchange", Currency: "usd", Amount: 100, Available: 100},
+	})
+
+	if len(balances) != 2 {
+		t.Fatalf("Test failed. Expected 2 balances. Actual %d", len(balances))
+	}
+
+	if b := balances["BTC"]; b.Available != 1 || b.Total != 1.5 {
+		t.Errorf("Test failed. Unexpected BTC balance: %+v", b)
+	}
+}
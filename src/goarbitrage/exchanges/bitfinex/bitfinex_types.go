@@ -30,4 +30,28 @@ type (
 		ExecutedAmount        float64 `json:"executed_amount,string"`
 		OrderID               int64   `json:"order_id"`
 	}
+
+	BitfinexBalance struct {
+		Type      string  `json:"type"`
+		Currency  string  `json:"currency"`
+		Amount    float64 `json:"amount,string"`
+		Available float64 `json:"available,string"`
+	}
+
+	BitfinexWebsocketSubscribe struct {
+		Event     string `json:"event"`
+		Channel   string `json:"channel"`
+		Symbol    string `json:"symbol"`
+		Precision string `json:"prec"`
+		Length    string `json:"len"`
+	}
+
+	BitfinexWebsocketEvent struct {
+		Event   string `json:"event"`
+		Channel string `json:"channel"`
+		ChanID  int64  `json:"chanId"`
+		Symbol  string `json:"symbol"`
+		Code    int64  `json:"code"`
+		Msg     string `json:"msg"`
+	}
 )
@@ -0,0 +1,254 @@
+package bitfinex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+)
+
+const (
+	BITFINEX_WEBSOCKET_URL          = "wss://api.bitfinex.com/ws/2"
+	BITFINEX_WEBSOCKET_BOOK         = "book"
+	BITFINEX_WEBSOCKET_HEARTBEAT    = "hb"
+	BITFINEX_WEBSOCKET_TIMEOUT      = 30 * time.Second
+	BITFINEX_WEBSOCKET_RECONNECT    = 5 * time.Second
+	BITFINEX_WEBSOCKET_INFO_RESTART = 20051
+)
+
+// BitfinexWebsocket keeps local order books from the v2 book channel, one
+// subscription per symbol on a single connection
+type BitfinexWebsocket struct {
+	URL            string
+	Symbols        []string
+	Verbose        bool
+	ReconnectDelay time.Duration
+	Books          map[string]*exchange.DepthBook
+
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	chanIDs map[int64]string
+	done    chan struct{}
+}
+
+func NewBitfinexWebsocket(url string, symbols []string) *BitfinexWebsocket {
+	books := map[string]*exchange.DepthBook{}
+	for _, symbol := range symbols {
+		books[common.StringToUpper(symbol)] = exchange.NewDepthBook()
+	}
+
+	return &BitfinexWebsocket{
+		URL:            url,
+		Symbols:        symbols,
+		ReconnectDelay: BITFINEX_WEBSOCKET_RECONNECT,
+		Books:          books,
+		done:           make(chan struct{}),
+	}
+}
+
+// OrderBook returns the streamed book of the symbol, ok is false until its
+// snapshot has been received
+func (w *BitfinexWebsocket) OrderBook(symbol string) (exchange.OrderBook, bool) {
+	book, ok := w.Books[common.StringToUpper(symbol)]
+	if !ok {
+		return exchange.OrderBook{}, false
+	}
+
+	return book.OrderBook()
+}
+
+// Start connects in background and keeps reconnecting until Close
+func (w *BitfinexWebsocket) Start() {
+	go w.run()
+}
+
+func (w *BitfinexWebsocket) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return
+	default:
+		close(w.done)
+	}
+
+	if w.conn != nil {
+		w.conn.Close()
+	}
+}
+
+func (w *BitfinexWebsocket) run() {
+	for {
+		err := w.connect()
+		for _, book := range w.Books {
+			book.Reset()
+		}
+
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+
+		log.Warn("Bitfinex websocket disconnected, falling back to REST", "warn", fmt.Sprint(err))
+
+		select {
+		case <-w.done:
+			return
+		case <-time.After(w.ReconnectDelay):
+		}
+	}
+}
+
+func (w *BitfinexWebsocket) connect() error {
+	dialer := websocket.Dialer{HandshakeTimeout: BITFINEX_WEBSOCKET_TIMEOUT}
+	conn, _, err := dialer.Dial(w.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	select {
+	case <-w.done:
+		w.mu.Unlock()
+		conn.Close()
+		return nil
+	default:
+	}
+	w.conn = conn
+	w.chanIDs = map[int64]string{}
+	w.mu.Unlock()
+
+	defer conn.Close()
+
+	for symbol := range w.Books {
+		err = conn.WriteJSON(BitfinexWebsocketSubscribe{
+			Event:     "subscribe",
+			Channel:   BITFINEX_WEBSOCKET_BOOK,
+			Symbol:    "t" + symbol,
+			Precision: "P0",
+			Length:    "25",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(BITFINEX_WEBSOCKET_TIMEOUT))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if w.Verbose {
+			log.Info("Bitfinex websocket recieved:", "info", string(data))
+		}
+
+		if err := w.handleMessage(data); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *BitfinexWebsocket) handleMessage(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		return w.handleEvent(data)
+	}
+
+	var message []json.RawMessage
+	if err := common.JSONDecode(data, &message); err != nil {
+		return err
+	}
+
+	if len(message) < 2 {
+		return nil
+	}
+
+	var chanID int64
+	if err := common.JSONDecode(message[0], &chanID); err != nil {
+		return err
+	}
+
+	book, ok := w.Books[w.chanIDs[chanID]]
+	if !ok {
+		return nil
+	}
+
+	var heartbeat string
+	if common.JSONDecode(message[1], &heartbeat) == nil && heartbeat == BITFINEX_WEBSOCKET_HEARTBEAT {
+		return nil
+	}
+
+	var snapshot [][3]float64
+	if err := common.JSONDecode(message[1], &snapshot); err == nil {
+		var bids, asks []exchange.ItemBook
+		for _, i := range snapshot {
+			item := exchange.ItemBook{Price: i[0], Amount: math.Abs(i[2])}
+			if i[2] > 0 {
+				bids = append(bids, item)
+			} else {
+				asks = append(asks, item)
+			}
+		}
+
+		book.Load(bids, asks)
+		return nil
+	}
+
+	var update [3]float64
+	if err := common.JSONDecode(message[1], &update); err != nil {
+		return err
+	}
+
+	if !book.IsReady() {
+		return errors.New("Bitfinex websocket: update before snapshot")
+	}
+
+	// A zero count removes the level, the sign of the amount tells the side
+	price, count, amount := update[0], update[1], update[2]
+	item := exchange.ItemBook{Price: price, Amount: math.Abs(amount)}
+	if count == 0 {
+		item.Amount = 0
+	}
+
+	if amount > 0 {
+		book.UpdateBid(item)
+	} else {
+		book.UpdateAsk(item)
+	}
+
+	return nil
+}
+
+func (w *BitfinexWebsocket) handleEvent(data []byte) error {
+	var event BitfinexWebsocketEvent
+	if err := common.JSONDecode(data, &event); err != nil {
+		return err
+	}
+
+	switch event.Event {
+	case "subscribed":
+		if event.Channel == BITFINEX_WEBSOCKET_BOOK {
+			w.chanIDs[event.ChanID] = strings.TrimPrefix(event.Symbol, "t")
+		}
+	case "error":
+		return fmt.Errorf("Bitfinex websocket error %d: %s", event.Code, event.Msg)
+	case "info":
+		if event.Code == BITFINEX_WEBSOCKET_INFO_RESTART {
+			return errors.New("Bitfinex websocket: server restart")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package bitfinex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"goarbitrage/exchanges"
+)
+
+func newFakeBookServer(t *testing.T, messages []string, subscribed chan<- string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Log(err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"info","version":2}`))
+
+		_, request, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		subscribed <- string(request)
+
+		for _, i := range messages {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(i)); err != nil {
+				return
+			}
+		}
+
+		// keep the connection open until the client goes away
+		conn.ReadMessage()
+	}))
+}
+
+func waitForBook(w *BitfinexWebsocket, check func(exchange.OrderBook) bool) (exchange.OrderBook, bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if book, ok := w.OrderBook("BTCUSD"); ok && check(book) {
+			return book, true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	book, _ := w.OrderBook("BTCUSD")
+	return book, false
+}
+
+func TestWebsocketBook(t *testing.T) {
+	subscribed := make(chan string, 1)
+	server := newFakeBookServer(t, []string{
+		`{"event":"subscribed","channel":"book","chanId":17,"symbol":"tBTCUSD","prec":"P0"}`,
+		`[17,[[1000,1,0.5],[999,2,1.5],[1001,1,-0.7],[1002,3,-2]]]`,
+		`[17,"hb"]`,
+		`[18,[998,1,5]]`,
+		`[17,[999,0,1]]`,
+		`[17,[1001,0,-1]]`,
+		`[17,[1000.5,1,0.25]]`,
+		`[17,[1002,2,-1]]`,
+	}, subscribed)
+	defer server.Close()
+
+	w := NewBitfinexWebsocket("ws"+strings.TrimPrefix(server.URL, "http"), []string{"BTCUSD"})
+	w.Start()
+	defer w.Close()
+
+	select {
+	case request := <-subscribed:
+		if !strings.Contains(request, `"symbol":"tBTCUSD"`) || !strings.Contains(request, `"channel":"book"`) {
+			t.Errorf("Test failed. Unexpected subscribe request: %s", request)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Test failed. No subscribe request recieved")
+	}
+
+	book, ok := waitForBook(w, func(book exchange.OrderBook) bool {
+		return len(book.Bids) == 2 && len(book.Asks) == 1 && book.Asks[0].Amount == 1
+	})
+	if !ok {
+		t.Fatalf("Test failed. Unexpected book: %+v", book)
+	}
+
+	if book.Bids[0].Price != 1000.5 || book.Bids[0].Amount != 0.25 || book.Bids[1].Price != 1000 {
+		t.Errorf("Test failed. Unexpected bids: %+v", book.Bids)
+	}
+
+	if book.Asks[0].Price != 1002 {
+		t.Errorf("Test failed. Unexpected asks: %+v", book.Asks)
+	}
+}
+
+func TestWebsocketDown(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	b := Bitfinex{}
+	b.SetDefaults()
+	b.Websocket = NewBitfinexWebsocket("ws"+strings.TrimPrefix(server.URL, "http"), []string{"BTCUSD"})
+	b.Websocket.Start()
+	defer b.Websocket.Close()
+
+	time.Sleep(50 * time.Millisecond)
+	if b.Websocket.Books["BTCUSD"].IsReady() {
+		t.Error("Test failed. Book should not be ready while the socket is down")
+	}
+}
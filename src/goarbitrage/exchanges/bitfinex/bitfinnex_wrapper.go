@@ -1,54 +1,166 @@
 package bitfinex
 
 import (
+	"context"
 	"fmt"
-	"net/url"
-	"sync"
+	"strconv"
 
 	"github.com/mgutz/logxi/v1"
 
+	"goarbitrage/common"
 	"goarbitrage/exchanges"
 )
 
-func (b *Bitfinex) UpdateDepth(wg *sync.WaitGroup, done chan struct{}, resp chan exchange.TaskResponse) {
-	defer wg.Done()
+func (b *Bitfinex) UpdateDepth(ctx context.Context, pair exchange.CurrencyPair) (exchange.OrderBook, error) {
+	symbol := b.FormatSymbol(pair)
+	if b.Verbose {
+		log.Info(fmt.Sprintf("%s polling %s", b.GetName(), symbol))
+	}
 
-	var (
-		values url.Values
-	)
+	if b.Websocket != nil {
+		if book, ok := b.Websocket.OrderBook(symbol); ok {
+			return book, nil
+		}
 
-	if b.Verbose {
-		log.Info(fmt.Sprintf("%s polling delay: %ds.\n", b.GetName(), b.RESTPollingDelay))
-		log.Info(fmt.Sprintf("%s currencies enabled: %s.\n", b.GetName(), b.Symbol))
-	}
-
-	for {
-		select {
-		case _, ok := <-done:
-			if !ok {
-				return
-			}
-		default:
-			book, err := b.GetOrderBook(b.GetSymbol(), values)
-			if err != nil {
-				log.Error(fmt.Sprintf("Error get order book %s(%s)", b.GetName(), b.Symbol), "error", err.Error())
-				return
-			}
-
-			var t exchange.OrderBook
-			for _, i := range book.Bids {
-				t.Bids = append(t.Bids, exchange.ItemBook(i))
-			}
-			for _, i := range book.Asks {
-				t.Asks = append(t.Asks, exchange.ItemBook(i))
-			}
-
-			resp <- exchange.TaskResponse{
-				Name:      b.Name,
-				OrderBook: t,
-			}
-
-			return
+		log.Warn(fmt.Sprintf("%s(%s) websocket book not ready, polling REST", b.GetName(), symbol), "warn")
+	}
+
+	book, err := b.GetOrderBook(ctx, symbol, nil)
+	if err != nil {
+		return exchange.OrderBook{}, err
+	}
+
+	var t exchange.OrderBook
+	for _, i := range book.Bids {
+		t.Bids = append(t.Bids, exchange.ItemBook(i))
+	}
+	for _, i := range book.Asks {
+		t.Asks = append(t.Asks, exchange.ItemBook(i))
+	}
+
+	return t, nil
+}
+
+func (b *Bitfinex) SubmitExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, side exchange.OrderSide, orderType exchange.OrderType, amount, price float64) (exchange.Order, error) {
+	var nativeType string
+	switch orderType {
+	case exchange.OrderTypeLimit:
+		nativeType = "exchange limit"
+	case exchange.OrderTypeMarket:
+		// Bitfinex requires a positive price even for market orders
+		nativeType = "exchange market"
+		if price <= 0 {
+			price = 1
+		}
+	default:
+		return exchange.Order{}, fmt.Errorf("%s: unsupported order type %s", b.Name, orderType)
+	}
+
+	order, err := b.NewOrder(ctx, b.FormatSymbol(pair), amount, price, side == exchange.SideBuy, nativeType, false)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromBitfinex(order), nil
+}
+
+func (b *Bitfinex) CancelExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("%s: invalid order id %s", b.Name, orderID)
+	}
+
+	order, err := b.CancelOrder(ctx, id)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromBitfinex(order), nil
+}
+
+func (b *Bitfinex) GetExchangeOrderInfo(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("%s: invalid order id %s", b.Name, orderID)
+	}
+
+	order, err := b.GetOrderStatus(ctx, id)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromBitfinex(order), nil
+}
+
+// GetOpenOrders returns the live orders of the account
+func (b *Bitfinex) GetOpenOrders(ctx context.Context) ([]exchange.Order, error) {
+	response, err := b.GetOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []exchange.Order
+	for _, o := range response {
+		orders = append(orders, orderFromBitfinex(o))
+	}
+
+	return orders, nil
+}
+
+func (b *Bitfinex) GetBalances(ctx context.Context) (map[string]exchange.Balance, error) {
+	balances, err := b.GetAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return balancesFromBitfinex(balances), nil
+}
+
+// balancesFromBitfinex keeps only the exchange wallet, deposit and trading
+// wallets can't be used for spot orders
+func balancesFromBitfinex(balances []BitfinexBalance) map[string]exchange.Balance {
+	result := map[string]exchange.Balance{}
+	for _, i := range balances {
+		if i.Type != "exchange" {
+			continue
+		}
+
+		currency := common.StringToUpper(i.Currency)
+		result[currency] = exchange.Balance{
+			Currency:  currency,
+			Available: i.Available,
+			Total:     i.Amount,
 		}
 	}
+
+	return result
+}
+
+func orderFromBitfinex(o BitfinexOrder) exchange.Order {
+	id := o.ID
+	if id == 0 {
+		id = o.OrderID
+	}
+
+	orderType := exchange.OrderTypeLimit
+	if common.StringContains(o.Type, "market") {
+		orderType = exchange.OrderTypeMarket
+	}
+
+	symbol := common.StringToUpper(o.Symbol)
+	if pair, err := pairFormat.Parse(o.Symbol); err == nil {
+		symbol = pair.String()
+	}
+
+	return exchange.Order{
+		ID:           strconv.FormatInt(id, 10),
+		Symbol:       symbol,
+		Side:         exchange.OrderSide(o.Side),
+		Type:         orderType,
+		Price:        o.Price,
+		Amount:       o.OriginalAmount,
+		FilledAmount: o.ExecutedAmount,
+		AvgPrice:     o.AverageExecutionPrice,
+		Status:       exchange.OrderStatusFromState(o.IsLive, o.IsCancelled, o.OriginalAmount, o.ExecutedAmount),
+	}
 }
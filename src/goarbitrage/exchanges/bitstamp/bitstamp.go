@@ -0,0 +1,265 @@
+package bitstamp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/config"
+	"goarbitrage/exchanges"
+)
+
+const (
+	BITSTAMP_API_URL     = "https://www.bitstamp.net/api"
+	BITSTAMP_API_VERSION = "2"
+
+	BITSTAMP_ORDERBOOK     = "order_book"
+	BITSTAMP_BALANCE       = "balance"
+	BITSTAMP_BUY           = "buy"
+	BITSTAMP_SELL          = "sell"
+	BITSTAMP_MARKET        = "market"
+	BITSTAMP_ORDER_CANCEL  = "cancel_order"
+	BITSTAMP_ORDER_STATUS  = "order_status"
+	BITSTAMP_OPEN_ORDERS   = "open_orders/all"
+	BITSTAMP_CONTENT_TYPE  = "application/x-www-form-urlencoded"
+	BITSTAMP_BALANCE_FREE  = "_available"
+	BITSTAMP_BALANCE_TOTAL = "_balance"
+)
+
+var (
+	// Bitstamp spells symbols in lower case without delimiter, btcusd
+	pairFormat = exchange.PairFormat{
+		Quotes: []string{"USD", "EUR", "GBP", "USDT", "USDC", "BTC", "ETH"},
+	}
+)
+
+type Bitstamp struct {
+	exchange.ExchangeBase
+}
+
+func init() {
+	exchange.Register("Bitstamp", func() exchange.IBotExchange {
+		return new(Bitstamp)
+	})
+}
+
+func (b *Bitstamp) SetDefaults() {
+	b.Name = "Bitstamp"
+	b.Enabled = false
+	b.Verbose = false
+	b.TakerFee = 0.25
+	b.MakerFee = 0.25
+	b.LotStep = 0.00000001
+	b.PairFormat = pairFormat
+	b.APIUrl = BITSTAMP_API_URL
+	b.SetRateLimits(config.RateLimit{Rate: 10, Burst: 20}, config.RateLimit{Rate: 10, Burst: 20})
+}
+
+func (b *Bitstamp) Setup(exch config.Exchange) {
+	if !exch.Enabled {
+		b.SetEnabled(false)
+		return
+	}
+
+	b.Enabled = true
+	b.AuthenticatedAPISupport = exch.AuthenticatedAPISupport
+	// the customer id is part of the signed message
+	b.SetAPIKeys(exch.APIKey, exch.APISecret, exch.ClientID, false)
+	b.RESTPollingDelay = exch.RESTPollingDelay.Duration
+	b.Verbose = exch.Verbose
+	b.SetEnabledPairs(exch.EnabledPairs)
+	b.SetFees(exch.TakerFee, exch.MakerFee)
+	b.SetHTTPTimeout(exch.HTTPTimeout.Duration)
+	b.SetRateLimits(exch.PublicRateLimit, exch.PrivateRateLimit)
+	b.DepthTimeout = exch.DepthTimeout.Duration
+	if exch.LotStep > 0 {
+		b.LotStep = exch.LotStep
+	}
+}
+
+func (b *Bitstamp) GetOrderBook(ctx context.Context, symbol string) (BitstampOrderBook, error) {
+	response := BitstampOrderBook{}
+	path := fmt.Sprintf("%s/v%s/%s/%s/", b.APIUrl, BITSTAMP_API_VERSION, BITSTAMP_ORDERBOOK, symbol)
+
+	err := b.SendPublicRequest(ctx, path, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// GetAccountBalances returns the available and total amount of every
+// currency, Bitstamp sends them flat as btc_available, btc_balance etc.
+func (b *Bitstamp) GetAccountBalances(ctx context.Context) (map[string]exchange.Balance, error) {
+	response := map[string]interface{}{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, BITSTAMP_BALANCE, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := map[string]exchange.Balance{}
+	for key, value := range response {
+		var currency string
+		switch {
+		case strings.HasSuffix(key, BITSTAMP_BALANCE_FREE):
+			currency = strings.TrimSuffix(key, BITSTAMP_BALANCE_FREE)
+		case strings.HasSuffix(key, BITSTAMP_BALANCE_TOTAL):
+			currency = strings.TrimSuffix(key, BITSTAMP_BALANCE_TOTAL)
+		default:
+			continue
+		}
+
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		amount, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid balance %s of %s", b.Name, text, key)
+		}
+
+		currency = common.StringToUpper(currency)
+		balance := balances[currency]
+		balance.Currency = currency
+		if strings.HasSuffix(key, BITSTAMP_BALANCE_FREE) {
+			balance.Available = amount
+		} else {
+			balance.Total = amount
+		}
+		balances[currency] = balance
+	}
+
+	return balances, nil
+}
+
+// NewOrder places a buy or sell order, a zero price places a market order
+func (b *Bitstamp) NewOrder(ctx context.Context, symbol string, buy bool, amount, price float64) (BitstampOrder, error) {
+	side := BITSTAMP_SELL
+	if buy {
+		side = BITSTAMP_BUY
+	}
+
+	values := url.Values{}
+	values.Set("amount", strconv.FormatFloat(amount, 'f', -1, 64))
+
+	method := side + "/" + symbol
+	if price > 0 {
+		values.Set("price", strconv.FormatFloat(price, 'f', -1, 64))
+	} else {
+		method = side + "/" + BITSTAMP_MARKET + "/" + symbol
+	}
+
+	response := BitstampOrder{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, method, values, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (b *Bitstamp) CancelOrder(ctx context.Context, orderID int64) (BitstampOrder, error) {
+	values := url.Values{}
+	values.Set("id", strconv.FormatInt(orderID, 10))
+
+	response := BitstampOrder{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, BITSTAMP_ORDER_CANCEL, values, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (b *Bitstamp) GetOrderStatus(ctx context.Context, orderID int64) (BitstampOrderStatus, error) {
+	values := url.Values{}
+	values.Set("id", strconv.FormatInt(orderID, 10))
+
+	response := BitstampOrderStatus{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, BITSTAMP_ORDER_STATUS, values, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (b *Bitstamp) GetOpenOrdersAll(ctx context.Context) ([]BitstampOrder, error) {
+	response := []BitstampOrder{}
+	err := b.SendAuthenticatedHTTPRequest(ctx, BITSTAMP_OPEN_ORDERS, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// SendAuthenticatedHTTPRequest posts the form signed with the upper cased hex
+// HMAC-SHA256 of nonce, customer id and API key
+func (b *Bitstamp) SendAuthenticatedHTTPRequest(ctx context.Context, method string, values url.Values, result interface{}) error {
+	if len(b.APIKey) == 0 {
+		return errors.New("SendAuthenticatedHTTPRequest: Invalid API key")
+	}
+
+	if len(b.ClientID) == 0 {
+		return errors.New("SendAuthenticatedHTTPRequest: Invalid customer ID")
+	}
+
+	if err := b.WaitRateLimit(ctx, exchange.ENDPOINT_PRIVATE); err != nil {
+		return err
+	}
+
+	if values == nil {
+		values = url.Values{}
+	}
+
+	nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
+	hmac := common.GetHMAC(common.HASH_SHA256, []byte(nonce+b.ClientID+b.APIKey), []byte(b.APISecret))
+
+	values.Set("key", b.APIKey)
+	values.Set("nonce", nonce)
+	values.Set("signature", common.StringToUpper(common.HexEncodeToString(hmac)))
+
+	path := fmt.Sprintf("%s/v%s/%s/", b.APIUrl, BITSTAMP_API_VERSION, method)
+	if b.Verbose {
+		log.Info("Request:", "info", path)
+	}
+
+	headers := make(map[string]string)
+	headers["Content-Type"] = BITSTAMP_CONTENT_TYPE
+
+	resp, err := b.GetHTTPClient().SendRequest(ctx, "POST", path, headers, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+
+	if b.Verbose {
+		log.Info("Recieved raw:", "info", resp)
+	}
+
+	errResponse := BitstampErrorResponse{}
+	if common.JSONDecode([]byte(resp), &errResponse) == nil {
+		if errResponse.Status == "error" {
+			return fmt.Errorf("SendAuthenticatedHTTPRequest: %s", string(errResponse.Reason))
+		}
+		if len(errResponse.Error) > 0 {
+			return fmt.Errorf("SendAuthenticatedHTTPRequest: %s", string(errResponse.Error))
+		}
+	}
+
+	err = common.JSONDecode([]byte(resp), &result)
+	if err != nil {
+		return errors.New("Unable to JSON Unmarshal response.")
+	}
+
+	return nil
+}
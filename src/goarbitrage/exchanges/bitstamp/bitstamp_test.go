@@ -0,0 +1,131 @@
+package bitstamp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+
+	"goarbitrage/exchanges"
+	"goarbitrage/exchanges/exchangetest"
+)
+
+// verify answers like Bitstamp to form posts which are not signed with
+// "secret" for customer 123456
+func verify(r *http.Request, body []byte) *exchangetest.Response {
+	if r.Method != "POST" {
+		return nil
+	}
+
+	r.ParseForm()
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(r.PostForm.Get("nonce") + "123456" + "key"))
+	expected := strings.ToUpper(hex.EncodeToString(mac.Sum(nil)))
+
+	if r.PostForm.Get("key") != "key" || r.PostForm.Get("signature") != expected {
+		return &exchangetest.Response{Body: `{"status":"error","reason":"Invalid signature","code":"API0005"}`}
+	}
+
+	return nil
+}
+
+func newTestBitstamp(t *testing.T, routes map[string]string) (*Bitstamp, *exchangetest.Server) {
+	server := exchangetest.NewServer(t, verify, routes)
+
+	b := &Bitstamp{}
+	b.SetDefaults()
+	server.Attach(&b.ExchangeBase)
+	b.EnabledPairs = []string{"BTC/USD"}
+	b.SetAPIKeys("key", "secret", "123456", false)
+	return b, server
+}
+
+func TestUpdateDepth(t *testing.T) {
+	b, server := newTestBitstamp(t, map[string]string{
+		"GET /v2/order_book/btcusd/": `{"timestamp":"1493640000","bids":[["1000.10","1.50000000"]],"asks":[["1001.00","0.25000000"],["1002.00","3.00000000"]]}`,
+	})
+	defer server.Close()
+
+	book, err := b.UpdateDepth(context.Background(), exchange.NewCurrencyPair("BTC", "USD"))
+	if err != nil {
+		t.Fatalf("Test failed. UpdateDepth() error: %s", err)
+	}
+
+	if len(book.Bids) != 1 || len(book.Asks) != 2 {
+		t.Fatalf("Test failed. Unexpected book: %+v", book)
+	}
+
+	if book.Bids[0].Price != 1000.1 || book.Bids[0].Amount != 1.5 || book.Asks[0].Timestamp != 1493640000 {
+		t.Errorf("Test failed. Unexpected book: %+v", book)
+	}
+}
+
+func TestSignedRequests(t *testing.T) {
+	b, server := newTestBitstamp(t, map[string]string{
+		"POST /v2/balance/": `{"btc_available":"0.50000000","btc_balance":"0.75000000","btc_reserved":"0.25000000",
+			"usd_available":"100.00","usd_balance":"100.00","btcusd_fee":"0.25","fee":0.25}`,
+		"POST /v2/sell/btcusd/": `{"id":"1234","datetime":"2017-05-01 10:00:00","type":"1","price":"1000.00","amount":"0.50000000"}`,
+	})
+	defer server.Close()
+
+	balances, err := b.GetBalances(context.Background())
+	if err != nil {
+		t.Fatalf("Test failed. GetBalances() error: %s", err)
+	}
+
+	if balances["BTC"].Available != 0.5 || balances["BTC"].Total != 0.75 || balances["USD"].Available != 100 {
+		t.Errorf("Test failed. Unexpected balances: %+v", balances)
+	}
+
+	order, err := b.SubmitExchangeOrder(context.Background(), exchange.NewCurrencyPair("BTC", "USD"), exchange.SideSell, exchange.OrderTypeLimit, 0.5, 1000)
+	if err != nil {
+		t.Fatalf("Test failed. SubmitExchangeOrder() error: %s", err)
+	}
+
+	if order.ID != "1234" || order.Side != exchange.SideSell || order.Amount != 0.5 || order.Symbol != "BTC/USD" {
+		t.Errorf("Test failed. Unexpected order: %+v", order)
+	}
+
+	b.APISecret = "wrong"
+	if _, err := b.GetBalances(context.Background()); err == nil || !strings.Contains(err.Error(), "Invalid signature") {
+		t.Errorf("Test failed. Expected invalid signature error. Actual %v", err)
+	}
+}
+
+func TestGetOpenOrders(t *testing.T) {
+	b, server := newTestBitstamp(t, map[string]string{
+		"POST /v2/open_orders/all/": `[{"id":"1234","datetime":"2017-05-01 10:00:00","type":"1","price":"1000.00","amount":"0.50000000","currency_pair":"BTC/USD"}]`,
+	})
+	defer server.Close()
+
+	orders, err := b.GetOpenOrders(context.Background())
+	if err != nil {
+		t.Fatalf("Test failed. GetOpenOrders() error: %s", err)
+	}
+
+	if len(orders) != 1 || orders[0].ID != "1234" || orders[0].Symbol != "BTC/USD" || orders[0].Side != exchange.SideSell {
+		t.Errorf("Test failed. Unexpected orders: %+v", orders)
+	}
+}
+
+func TestOrderFromBitstampStatus(t *testing.T) {
+	order := orderFromBitstampStatus(exchange.NewCurrencyPair("BTC", "USD"), "1234", BitstampOrderStatus{
+		Status:          "Open",
+		AmountRemaining: 0.5,
+		Transactions: []BitstampTransaction{
+			{"price": "1000.00", "btc": "0.25", "usd": "250.00"},
+			{"price": "1002.00", "btc": 0.25, "usd": "250.50"},
+		},
+	})
+
+	if order.FilledAmount != 0.5 || order.Amount != 1 || order.AvgPrice != 1001 {
+		t.Errorf("Test failed. Unexpected fills: %+v", order)
+	}
+
+	if order.Status != exchange.OrderStatusPartiallyFilled {
+		t.Errorf("Test failed. Expected %s. Actual %s", exchange.OrderStatusPartiallyFilled, order.Status)
+	}
+}
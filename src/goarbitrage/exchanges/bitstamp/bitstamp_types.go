@@ -0,0 +1,82 @@
+package bitstamp
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+type (
+	// BitstampBookEntry is a [price, amount] array of strings
+	BitstampBookEntry struct {
+		Price  float64
+		Amount float64
+	}
+
+	BitstampOrderBook struct {
+		Timestamp int64               `json:"timestamp,string"`
+		Bids      []BitstampBookEntry `json:"bids"`
+		Asks      []BitstampBookEntry `json:"asks"`
+	}
+
+	BitstampErrorResponse struct {
+		Status string          `json:"status"`
+		Reason json.RawMessage `json:"reason"`
+		Error  json.RawMessage `json:"error"`
+	}
+
+	BitstampOrder struct {
+		ID       int64   `json:"id,string"`
+		DateTime string  `json:"datetime"`
+		Type     int     `json:"type,string"`
+		Price    float64 `json:"price,string"`
+		Amount   float64 `json:"amount,string"`
+		// CurrencyPair is only listed with the open orders, BTC/USD
+		CurrencyPair string `json:"currency_pair"`
+	}
+
+	// BitstampTransaction amounts are keyed by the lower cased currency
+	BitstampTransaction map[string]interface{}
+
+	BitstampOrderStatus struct {
+		ID              int64                 `json:"id"`
+		Status          string                `json:"status"`
+		AmountRemaining float64               `json:"amount_remaining,string"`
+		Transactions    []BitstampTransaction `json:"transactions"`
+	}
+)
+
+func (e *BitstampBookEntry) UnmarshalJSON(data []byte) error {
+	var entry []string
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+
+	if len(entry) < 2 {
+		return errors.New("Bitstamp book entry: expected [price, amount]")
+	}
+
+	var err error
+	if e.Price, err = strconv.ParseFloat(entry[0], 64); err != nil {
+		return err
+	}
+	if e.Amount, err = strconv.ParseFloat(entry[1], 64); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Float returns the transaction value of the key, Bitstamp sends both
+// strings and numbers
+func (t BitstampTransaction) Float(key string) float64 {
+	switch v := t[key].(type) {
+	case string:
+		value, _ := strconv.ParseFloat(v, 64)
+		return value
+	case float64:
+		return v
+	}
+
+	return 0
+}
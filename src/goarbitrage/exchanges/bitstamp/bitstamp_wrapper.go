@@ -0,0 +1,167 @@
+package bitstamp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+)
+
+const (
+	BITSTAMP_ORDER_BUY  = 0
+	BITSTAMP_ORDER_SELL = 1
+)
+
+func (b *Bitstamp) UpdateDepth(ctx context.Context, pair exchange.CurrencyPair) (exchange.OrderBook, error) {
+	symbol := b.FormatSymbol(pair)
+	if b.Verbose {
+		log.Info(fmt.Sprintf("%s polling %s", b.GetName(), symbol))
+	}
+
+	book, err := b.GetOrderBook(ctx, symbol)
+	if err != nil {
+		return exchange.OrderBook{}, err
+	}
+
+	timestamp := float64(book.Timestamp)
+
+	var t exchange.OrderBook
+	for _, i := range book.Bids {
+		t.Bids = append(t.Bids, exchange.ItemBook{Price: i.Price, Amount: i.Amount, Timestamp: timestamp})
+	}
+	for _, i := range book.Asks {
+		t.Asks = append(t.Asks, exchange.ItemBook{Price: i.Price, Amount: i.Amount, Timestamp: timestamp})
+	}
+
+	return t, nil
+}
+
+func (b *Bitstamp) SubmitExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, side exchange.OrderSide, orderType exchange.OrderType, amount, price float64) (exchange.Order, error) {
+	switch orderType {
+	case exchange.OrderTypeLimit:
+		if price <= 0 {
+			return exchange.Order{}, fmt.Errorf("%s: limit order without price", b.Name)
+		}
+	case exchange.OrderTypeMarket:
+		price = 0
+	default:
+		return exchange.Order{}, fmt.Errorf("%s: unsupported order type %s", b.Name, orderType)
+	}
+
+	order, err := b.NewOrder(ctx, b.FormatSymbol(pair), side == exchange.SideBuy, amount, price)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	result := orderFromBitstamp(pair, order)
+	result.Type = orderType
+	return result, nil
+}
+
+func (b *Bitstamp) CancelExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("%s: invalid order id %s", b.Name, orderID)
+	}
+
+	order, err := b.CancelOrder(ctx, id)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	result := orderFromBitstamp(pair, order)
+	result.Status = exchange.OrderStatusCancelled
+	return result, nil
+}
+
+func (b *Bitstamp) GetExchangeOrderInfo(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("%s: invalid order id %s", b.Name, orderID)
+	}
+
+	status, err := b.GetOrderStatus(ctx, id)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromBitstampStatus(pair, orderID, status), nil
+}
+
+// GetOpenOrders returns the open orders of every pair of the account
+func (b *Bitstamp) GetOpenOrders(ctx context.Context) ([]exchange.Order, error) {
+	response, err := b.GetOpenOrdersAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []exchange.Order
+	for _, o := range response {
+		pair, err := exchange.ParseCurrencyPair(o.CurrencyPair)
+		if err != nil {
+			return nil, fmt.Errorf("%s: order %d: %s", b.Name, o.ID, err)
+		}
+		orders = append(orders, orderFromBitstamp(pair, o))
+	}
+
+	return orders, nil
+}
+
+func (b *Bitstamp) GetBalances(ctx context.Context) (map[string]exchange.Balance, error) {
+	return b.GetAccountBalances(ctx)
+}
+
+func orderFromBitstamp(pair exchange.CurrencyPair, o BitstampOrder) exchange.Order {
+	side := exchange.SideBuy
+	if o.Type == BITSTAMP_ORDER_SELL {
+		side = exchange.SideSell
+	}
+
+	return exchange.Order{
+		ID:     strconv.FormatInt(o.ID, 10),
+		Symbol: pair.String(),
+		Side:   side,
+		Type:   exchange.OrderTypeLimit,
+		Price:  o.Price,
+		Amount: o.Amount,
+		Status: exchange.OrderStatusOpen,
+	}
+}
+
+// orderFromBitstampStatus sums the fills of the order, the status doesn't
+// tell the side nor the original amount besides what is left
+func orderFromBitstampStatus(pair exchange.CurrencyPair, id string, s BitstampOrderStatus) exchange.Order {
+	var filled, cost float64
+	for _, i := range s.Transactions {
+		amount := i.Float(common.StringToLower(pair.Base))
+		filled += amount
+		cost += amount * i.Float("price")
+	}
+
+	var avgPrice float64
+	if filled > 0 {
+		avgPrice = cost / filled
+	}
+
+	isLive := s.Status == "Open" || s.Status == "In Queue"
+	isCancelled := s.Status == "Canceled"
+	amount := filled + s.AmountRemaining
+
+	status := exchange.OrderStatusFromState(isLive, isCancelled, amount, filled)
+	if s.Status == "Finished" {
+		status = exchange.OrderStatusFilled
+	}
+
+	return exchange.Order{
+		ID:           id,
+		Symbol:       pair.String(),
+		Amount:       amount,
+		FilledAmount: filled,
+		AvgPrice:     avgPrice,
+		Status:       status,
+	}
+}
@@ -0,0 +1,226 @@
+package coinbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/config"
+	"goarbitrage/exchanges"
+)
+
+const (
+	COINBASE_API_URL = "https://api.gdax.com"
+
+	COINBASE_PRODUCTS   = "products"
+	COINBASE_ORDERBOOK  = "book"
+	COINBASE_ORDERS     = "orders"
+	COINBASE_ACCOUNTS   = "accounts"
+	COINBASE_USER_AGENT = "goarbitrage"
+)
+
+var (
+	// Coinbase product ids are upper cased with a dash, BTC-USD
+	pairFormat = exchange.PairFormat{
+		Delimiter: "-",
+		Uppercase: true,
+	}
+)
+
+type Coinbase struct {
+	exchange.ExchangeBase
+}
+
+func init() {
+	exchange.Register("Coinbase", func() exchange.IBotExchange {
+		return new(Coinbase)
+	})
+}
+
+func (c *Coinbase) SetDefaults() {
+	c.Name = "Coinbase"
+	c.Enabled = false
+	c.Verbose = false
+	c.TakerFee = 0.25
+	c.MakerFee = 0
+	c.LotStep = 0.00000001
+	c.PairFormat = pairFormat
+	c.APIUrl = COINBASE_API_URL
+	c.SetRateLimits(config.RateLimit{Rate: 3, Burst: 6}, config.RateLimit{Rate: 5, Burst: 10})
+}
+
+func (c *Coinbase) Setup(exch config.Exchange) {
+	if !exch.Enabled {
+		c.SetEnabled(false)
+		return
+	}
+
+	c.Enabled = true
+	c.AuthenticatedAPISupport = exch.AuthenticatedAPISupport
+	// the API passphrase is kept in the client id, the secret is base64
+	c.SetAPIKeys(exch.APIKey, exch.APISecret, exch.ClientID, true)
+	c.RESTPollingDelay = exch.RESTPollingDelay.Duration
+	c.Verbose = exch.Verbose
+	c.SetEnabledPairs(exch.EnabledPairs)
+	c.SetFees(exch.TakerFee, exch.MakerFee)
+	c.SetHTTPTimeout(exch.HTTPTimeout.Duration)
+	c.SetRateLimits(exch.PublicRateLimit, exch.PrivateRateLimit)
+	c.DepthTimeout = exch.DepthTimeout.Duration
+	if exch.LotStep > 0 {
+		c.LotStep = exch.LotStep
+	}
+}
+
+func (c *Coinbase) GetOrderBook(ctx context.Context, product string, level int) (CoinbaseOrderBook, error) {
+	values := url.Values{}
+	values.Set("level", strconv.Itoa(level))
+
+	path := common.EncodeURLValues(fmt.Sprintf("%s/%s/%s/%s", c.APIUrl, COINBASE_PRODUCTS, product, COINBASE_ORDERBOOK), values)
+
+	response := CoinbaseOrderBook{}
+	err := c.SendPublicRequest(ctx, path, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (c *Coinbase) GetProducts() ([]CoinbaseProduct, error) {
+	products := []CoinbaseProduct{}
+	err := c.SendPublicRequest(context.Background(), fmt.Sprintf("%s/%s", c.APIUrl, COINBASE_PRODUCTS), &products)
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (c *Coinbase) GetSymbols() ([]exchange.CurrencyPair, error) {
+	products, err := c.GetProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []exchange.CurrencyPair{}
+	for _, i := range products {
+		pairs = append(pairs, exchange.NewCurrencyPair(i.BaseCurrency, i.QuoteCurrency))
+	}
+	return pairs, nil
+}
+
+func (c *Coinbase) GetAccounts(ctx context.Context) ([]CoinbaseAccount, error) {
+	response := []CoinbaseAccount{}
+	err := c.SendAuthenticatedHTTPRequest(ctx, "GET", COINBASE_ACCOUNTS, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Coinbase) NewOrder(ctx context.Context, order CoinbaseNewOrder) (CoinbaseOrder, error) {
+	response := CoinbaseOrder{}
+	err := c.SendAuthenticatedHTTPRequest(ctx, "POST", COINBASE_ORDERS, order, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (c *Coinbase) CancelOrder(ctx context.Context, orderID string) error {
+	response := []string{}
+	return c.SendAuthenticatedHTTPRequest(ctx, "DELETE", COINBASE_ORDERS+"/"+orderID, nil, &response)
+}
+
+func (c *Coinbase) GetOrder(ctx context.Context, orderID string) (CoinbaseOrder, error) {
+	response := CoinbaseOrder{}
+	err := c.SendAuthenticatedHTTPRequest(ctx, "GET", COINBASE_ORDERS+"/"+orderID, nil, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// GetOrders lists the orders of the account still open or waiting to be
+// opened
+func (c *Coinbase) GetOrders(ctx context.Context) ([]CoinbaseOrder, error) {
+	values := url.Values{}
+	values.Set("status", "open")
+	values.Add("status", "pending")
+
+	response := []CoinbaseOrder{}
+	err := c.SendAuthenticatedHTTPRequest(ctx, "GET", common.EncodeURLValues(COINBASE_ORDERS, values), nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// SendAuthenticatedHTTPRequest signs timestamp, method, request path and body
+// with HMAC-SHA256 of the base64 decoded secret
+func (c *Coinbase) SendAuthenticatedHTTPRequest(ctx context.Context, method, path string, params interface{}, result interface{}) error {
+	if len(c.APIKey) == 0 {
+		return errors.New("SendAuthenticatedHTTPRequest: Invalid API key")
+	}
+
+	if err := c.WaitRateLimit(ctx, exchange.ENDPOINT_PRIVATE); err != nil {
+		return err
+	}
+
+	var payload []byte
+	if params != nil {
+		var err error
+		payload, err = common.JSONEncode(params)
+		if err != nil {
+			return errors.New("SendAuthenticatedHTTPRequest: Unable to JSON request")
+		}
+
+		if c.Verbose {
+			log.Info("Request JSON:", "info", string(payload))
+		}
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	message := timestamp + method + "/" + path + string(payload)
+	hmac := common.GetHMAC(common.HASH_SHA256, []byte(message), []byte(c.APISecret))
+
+	headers := make(map[string]string)
+	headers["CB-ACCESS-KEY"] = c.APIKey
+	headers["CB-ACCESS-SIGN"] = common.Base64Encode(hmac)
+	headers["CB-ACCESS-TIMESTAMP"] = timestamp
+	headers["CB-ACCESS-PASSPHRASE"] = c.ClientID
+	headers["Content-Type"] = "application/json"
+	headers["User-Agent"] = COINBASE_USER_AGENT
+
+	resp, err := c.GetHTTPClient().SendRequest(ctx, method, c.APIUrl+"/"+path, headers, strings.NewReader(string(payload)))
+	if err != nil {
+		return err
+	}
+
+	if c.Verbose {
+		log.Info("Recieved raw:", "info", resp)
+	}
+
+	errResponse := CoinbaseErrorResponse{}
+	if common.JSONDecode([]byte(resp), &errResponse) == nil && errResponse.Message != "" {
+		return fmt.Errorf("SendAuthenticatedHTTPRequest: %s", errResponse.Message)
+	}
+
+	err = common.JSONDecode([]byte(resp), &result)
+	if err != nil {
+		return errors.New("Unable to JSON Unmarshal response.")
+	}
+
+	return nil
+}
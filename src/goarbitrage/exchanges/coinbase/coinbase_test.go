@@ -0,0 +1,159 @@
+package coinbase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+	"goarbitrage/exchanges/exchangetest"
+)
+
+const (
+	testSecret = "c2VjcmV0LWtleQ=="
+)
+
+// verify refuses private requests which are not signed with testSecret
+func verify(r *http.Request, body []byte) *exchangetest.Response {
+	if r.Header.Get("CB-ACCESS-KEY") == "" {
+		return nil
+	}
+
+	secret, _ := base64.StdEncoding.DecodeString(testSecret)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(r.Header.Get("CB-ACCESS-TIMESTAMP") + r.Method + r.URL.RequestURI() + string(body)))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if r.Header.Get("CB-ACCESS-SIGN") != expected || r.Header.Get("CB-ACCESS-PASSPHRASE") != "passphrase" {
+		return &exchangetest.Response{Status: http.StatusUnauthorized, Body: `{"message":"invalid signature"}`}
+	}
+
+	return nil
+}
+
+func newTestCoinbase(t *testing.T, routes map[string]string) (*Coinbase, *exchangetest.Server) {
+	server := exchangetest.NewServer(t, verify, routes)
+
+	c := &Coinbase{}
+	c.SetDefaults()
+	server.Attach(&c.ExchangeBase)
+	c.EnabledPairs = []string{"BTC/USD"}
+	c.SetAPIKeys("key", testSecret, "passphrase", true)
+	return c, server
+}
+
+func TestUpdateDepth(t *testing.T) {
+	c, server := newTestCoinbase(t, map[string]string{
+		"GET /products/BTC-USD/book": `{"sequence":3,"bids":[["1000.10","1.5",3]],"asks":[["1001.00","0.25",1],["1002.50","2",4]]}`,
+	})
+	defer server.Close()
+
+	book, err := c.UpdateDepth(context.Background(), exchange.NewCurrencyPair("BTC", "USD"))
+	if err != nil {
+		t.Fatalf("Test failed. UpdateDepth() error: %s", err)
+	}
+
+	if len(book.Bids) != 1 || len(book.Asks) != 2 {
+		t.Fatalf("Test failed. Unexpected book: %+v", book)
+	}
+
+	if book.Bids[0].Price != 1000.1 || book.Bids[0].Amount != 1.5 || book.Asks[1].Price != 1002.5 {
+		t.Errorf("Test failed. Unexpected book: %+v", book)
+	}
+}
+
+func TestSignedRequests(t *testing.T) {
+	c, server := newTestCoinbase(t, map[string]string{
+		"GET /accounts": `[{"id":"1","currency":"BTC","balance":"1.5","available":"1.0","hold":"0.5"},
+			{"id":"2","currency":"USD","balance":"100.00","available":"100.00","hold":"0"}]`,
+		"POST /orders": `{"id":"d0c5340b-6d6c-49d9-b567-48c4bfca13d2","price":"1000.00","size":"0.50","product_id":"BTC-USD",
+			"side":"buy","type":"limit","status":"pending","filled_size":"0","executed_value":"0","settled":false}`,
+	})
+	defer server.Close()
+
+	balances, err := c.GetBalances(context.Background())
+	if err != nil {
+		t.Fatalf("Test failed. GetBalances() error: %s", err)
+	}
+
+	if balances["BTC"].Available != 1 || balances["BTC"].Total != 1.5 || balances["USD"].Available != 100 {
+		t.Errorf("Test failed. Unexpected balances: %+v", balances)
+	}
+
+	order, err := c.SubmitExchangeOrder(context.Background(), exchange.NewCurrencyPair("BTC", "USD"), exchange.SideBuy, exchange.OrderTypeLimit, 0.5, 1000)
+	if err != nil {
+		t.Fatalf("Test failed. SubmitExchangeOrder() error: %s", err)
+	}
+
+	if order.ID != "d0c5340b-6d6c-49d9-b567-48c4bfca13d2" || order.Symbol != "BTC/USD" || order.Status != exchange.OrderStatusOpen {
+		t.Errorf("Test failed. Unexpected order: %+v", order)
+	}
+
+	c.APISecret = "wrong"
+	if _, err := c.GetBalances(context.Background()); err == nil || !common.StringContains(err.Error(), "invalid signature") {
+		t.Errorf("Test failed. Expected invalid signature error. Actual %v", err)
+	}
+}
+
+func TestCancelExchangeOrder(t *testing.T) {
+	c, server := newTestCoinbase(t, map[string]string{
+		"DELETE /orders/1": `["1"]`,
+		"DELETE /orders/2": `["2"]`,
+	})
+	defer server.Close()
+	server.Respond("GET /orders/1", exchangetest.Response{Status: http.StatusNotFound, Body: `{"message":"NotFound"}`})
+	server.Respond("GET /orders/2", exchangetest.Response{Status: http.StatusServiceUnavailable, Body: `{"message":"unavailable"}`})
+
+	pair := exchange.NewCurrencyPair("BTC", "USD")
+	order, err := c.CancelExchangeOrder(context.Background(), pair, "1")
+	if err != nil || order.Status != exchange.OrderStatusCancelled {
+		t.Errorf("Test failed. Expected purged order to be cancelled. Actual %+v, %v", order, err)
+	}
+
+	if _, err := c.CancelExchangeOrder(context.Background(), pair, "2"); err == nil {
+		t.Error("Test failed. Expected the failed order query to be returned")
+	}
+}
+
+func TestGetOpenOrders(t *testing.T) {
+	c, server := newTestCoinbase(t, map[string]string{
+		"GET /orders": `[{"id":"1","price":"1000.00","size":"0.50","product_id":"BTC-USD","side":"sell","type":"limit","status":"open","filled_size":"0.10"}]`,
+	})
+	defer server.Close()
+
+	orders, err := c.GetOpenOrders(context.Background())
+	if err != nil {
+		t.Fatalf("Test failed. GetOpenOrders() error: %s", err)
+	}
+
+	if len(orders) != 1 || orders[0].Symbol != "BTC/USD" || orders[0].Status != exchange.OrderStatusPartiallyFilled {
+		t.Errorf("Test failed. Unexpected orders: %+v", orders)
+	}
+
+	if requests := server.Requests(); len(requests) != 1 || requests[0] != "GET /orders?status=open&status=pending" {
+		t.Errorf("Test failed. Expected open and pending orders to be requested. Actual %v", requests)
+	}
+}
+
+func TestOrderFromCoinbase(t *testing.T) {
+	order := orderFromCoinbase(CoinbaseOrder{
+		ID:            "1",
+		Price:         1000,
+		Size:          2,
+		ProductID:     "BTC-USD",
+		Side:          "sell",
+		Type:          "limit",
+		Status:        "done",
+		DoneReason:    "filled",
+		FilledSize:    2,
+		ExecutedValue: 2001,
+	})
+
+	if order.Status != exchange.OrderStatusFilled || order.AvgPrice != 1000.5 || order.Side != exchange.SideSell {
+		t.Errorf("Test failed. Unexpected order: %+v", order)
+	}
+}
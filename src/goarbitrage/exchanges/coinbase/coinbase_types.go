@@ -0,0 +1,98 @@
+package coinbase
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+type (
+	// CoinbaseBookEntry is a [price, size, num-orders] array of the level 2
+	// book, prices and sizes are strings
+	CoinbaseBookEntry struct {
+		Price  float64
+		Amount float64
+		Orders int64
+	}
+
+	CoinbaseOrderBook struct {
+		Sequence int64               `json:"sequence"`
+		Bids     []CoinbaseBookEntry `json:"bids"`
+		Asks     []CoinbaseBookEntry `json:"asks"`
+	}
+
+	CoinbaseErrorResponse struct {
+		Message string `json:"message"`
+	}
+
+	CoinbaseProduct struct {
+		ID            string `json:"id"`
+		BaseCurrency  string `json:"base_currency"`
+		QuoteCurrency string `json:"quote_currency"`
+	}
+
+	CoinbaseAccount struct {
+		ID        string  `json:"id"`
+		Currency  string  `json:"currency"`
+		Balance   float64 `json:"balance,string"`
+		Available float64 `json:"available,string"`
+		Hold      float64 `json:"hold,string"`
+	}
+
+	CoinbaseNewOrder struct {
+		Type      string `json:"type"`
+		Side      string `json:"side"`
+		ProductID string `json:"product_id"`
+		Price     string `json:"price,omitempty"`
+		Size      string `json:"size"`
+	}
+
+	CoinbaseOrder struct {
+		ID            string  `json:"id"`
+		Price         float64 `json:"price,string"`
+		Size          float64 `json:"size,string"`
+		ProductID     string  `json:"product_id"`
+		Side          string  `json:"side"`
+		Type          string  `json:"type"`
+		Status        string  `json:"status"`
+		DoneReason    string  `json:"done_reason"`
+		FilledSize    float64 `json:"filled_size,string"`
+		ExecutedValue float64 `json:"executed_value,string"`
+		Settled       bool    `json:"settled"`
+	}
+)
+
+func (e *CoinbaseBookEntry) UnmarshalJSON(data []byte) error {
+	var entry []interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+
+	if len(entry) < 3 {
+		return errors.New("Coinbase book entry: expected [price, size, num-orders]")
+	}
+
+	price, ok := entry[0].(string)
+	if !ok {
+		return errors.New("Coinbase book entry: price is not a string")
+	}
+	size, ok := entry[1].(string)
+	if !ok {
+		return errors.New("Coinbase book entry: size is not a string")
+	}
+	orders, ok := entry[2].(float64)
+	if !ok {
+		return errors.New("Coinbase book entry: num-orders is not a number")
+	}
+
+	var err error
+	if e.Price, err = strconv.ParseFloat(price, 64); err != nil {
+		return err
+	}
+	if e.Amount, err = strconv.ParseFloat(size, 64); err != nil {
+		return err
+	}
+	e.Orders = int64(orders)
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package coinbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+)
+
+const (
+	COINBASE_BOOK_LEVEL = 2
+)
+
+func (c *Coinbase) UpdateDepth(ctx context.Context, pair exchange.CurrencyPair) (exchange.OrderBook, error) {
+	product := c.FormatSymbol(pair)
+	if c.Verbose {
+		log.Info(fmt.Sprintf("%s polling %s", c.GetName(), product))
+	}
+
+	book, err := c.GetOrderBook(ctx, product, COINBASE_BOOK_LEVEL)
+	if err != nil {
+		return exchange.OrderBook{}, err
+	}
+
+	var t exchange.OrderBook
+	for _, i := range book.Bids {
+		t.Bids = append(t.Bids, exchange.ItemBook{Price: i.Price, Amount: i.Amount})
+	}
+	for _, i := range book.Asks {
+		t.Asks = append(t.Asks, exchange.ItemBook{Price: i.Price, Amount: i.Amount})
+	}
+
+	return t, nil
+}
+
+func (c *Coinbase) SubmitExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, side exchange.OrderSide, orderType exchange.OrderType, amount, price float64) (exchange.Order, error) {
+	request := CoinbaseNewOrder{
+		Side:      string(side),
+		ProductID: c.FormatSymbol(pair),
+		Size:      strconv.FormatFloat(amount, 'f', -1, 64),
+	}
+
+	switch orderType {
+	case exchange.OrderTypeLimit:
+		request.Type = "limit"
+		request.Price = strconv.FormatFloat(price, 'f', -1, 64)
+	case exchange.OrderTypeMarket:
+		request.Type = "market"
+	default:
+		return exchange.Order{}, fmt.Errorf("%s: unsupported order type %s", c.Name, orderType)
+	}
+
+	order, err := c.NewOrder(ctx, request)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromCoinbase(order), nil
+}
+
+func (c *Coinbase) CancelExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	if err := c.CancelOrder(ctx, orderID); err != nil {
+		return exchange.Order{}, err
+	}
+
+	// cancelled orders without fills are purged and answer 404
+	order, err := c.GetOrder(ctx, orderID)
+	var httpErr *common.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return exchange.Order{
+			ID:     orderID,
+			Symbol: pair.String(),
+			Status: exchange.OrderStatusCancelled,
+		}, nil
+	}
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromCoinbase(order), nil
+}
+
+func (c *Coinbase) GetExchangeOrderInfo(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	order, err := c.GetOrder(ctx, orderID)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromCoinbase(order), nil
+}
+
+// GetOpenOrders returns the open and pending orders of the account
+func (c *Coinbase) GetOpenOrders(ctx context.Context) ([]exchange.Order, error) {
+	response, err := c.GetOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []exchange.Order
+	for _, o := range response {
+		orders = append(orders, orderFromCoinbase(o))
+	}
+
+	return orders, nil
+}
+
+func (c *Coinbase) GetBalances(ctx context.Context) (map[string]exchange.Balance, error) {
+	accounts, err := c.GetAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]exchange.Balance{}
+	for _, i := range accounts {
+		currency := common.StringToUpper(i.Currency)
+		result[currency] = exchange.Balance{
+			Currency:  currency,
+			Available: i.Available,
+			Total:     i.Balance,
+		}
+	}
+
+	return result, nil
+}
+
+func orderFromCoinbase(o CoinbaseOrder) exchange.Order {
+	orderType := exchange.OrderTypeLimit
+	if o.Type == "market" {
+		orderType = exchange.OrderTypeMarket
+	}
+
+	symbol := o.ProductID
+	if pair, err := pairFormat.Parse(o.ProductID); err == nil {
+		symbol = pair.String()
+	}
+
+	var avgPrice float64
+	if o.FilledSize > 0 {
+		avgPrice = o.ExecutedValue / o.FilledSize
+	}
+
+	isLive := o.Status == "pending" || o.Status == "open" || o.Status == "active"
+	isCancelled := o.Status == "done" && o.DoneReason == "canceled"
+
+	return exchange.Order{
+		ID:           o.ID,
+		Symbol:       symbol,
+		Side:         exchange.OrderSide(o.Side),
+		Type:         orderType,
+		Price:        o.Price,
+		Amount:       o.Size,
+		FilledAmount: o.FilledSize,
+		AvgPrice:     avgPrice,
+		Status:       exchange.OrderStatusFromState(isLive, isCancelled, o.Size, o.FilledSize),
+	}
+}
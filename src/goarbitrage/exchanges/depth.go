@@ -0,0 +1,105 @@
+package exchange
+
+import (
+	"sort"
+	"sync"
+)
+
+// DepthBook is a local price level book maintained from streamed snapshots
+// and deltas. It is not ready until the first snapshot is loaded and after
+// a Reset, so callers can fall back to REST polling meanwhile.
+type DepthBook struct {
+	mu    sync.RWMutex
+	bids  map[float64]ItemBook
+	asks  map[float64]ItemBook
+	ready bool
+}
+
+func NewDepthBook() *DepthBook {
+	return &DepthBook{
+		bids: map[float64]ItemBook{},
+		asks: map[float64]ItemBook{},
+	}
+}
+
+// Load replaces the book with a snapshot and marks it ready
+func (d *DepthBook) Load(bids, asks []ItemBook) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.bids = map[float64]ItemBook{}
+	d.asks = map[float64]ItemBook{}
+	for _, i := range bids {
+		d.bids[i.Price] = i
+	}
+	for _, i := range asks {
+		d.asks[i.Price] = i
+	}
+
+	d.ready = true
+}
+
+// Reset drops every level and marks the book as not ready
+func (d *DepthBook) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.bids = map[float64]ItemBook{}
+	d.asks = map[float64]ItemBook{}
+	d.ready = false
+}
+
+// UpdateBid sets the amount of the bid price level, a zero amount removes it
+func (d *DepthBook) UpdateBid(item ItemBook) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	updateLevel(d.bids, item)
+}
+
+// UpdateAsk sets the amount of the ask price level, a zero amount removes it
+func (d *DepthBook) UpdateAsk(item ItemBook) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	updateLevel(d.asks, item)
+}
+
+func (d *DepthBook) IsReady() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.ready
+}
+
+// OrderBook returns a copy of the book with bids sorted from the highest
+// price and asks from the lowest, ok is false when the book isn't ready
+func (d *DepthBook) OrderBook() (OrderBook, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if !d.ready {
+		return OrderBook{}, false
+	}
+
+	var book OrderBook
+	for _, i := range d.bids {
+		book.Bids = append(book.Bids, i)
+	}
+	for _, i := range d.asks {
+		book.Asks = append(book.Asks, i)
+	}
+
+	sort.Slice(book.Bids, func(i, j int) bool { return book.Bids[i].Price > book.Bids[j].Price })
+	sort.Slice(book.Asks, func(i, j int) bool { return book.Asks[i].Price < book.Asks[j].Price })
+	return book, true
+}
+
+func updateLevel(levels map[float64]ItemBook, item ItemBook) {
+	if item.Amount == 0 {
+		delete(levels, item.Price)
+		return
+	}
+
+	levels[item.Price] = item
+}
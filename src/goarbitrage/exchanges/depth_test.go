@@ -0,0 +1,37 @@
+package exchange
+
+import (
+	"testing"
+)
+
+func TestDepthBook(t *testing.T) {
+	d := NewDepthBook()
+	if _, ok := d.OrderBook(); ok {
+		t.Error("Test failed. DepthBook should not be ready before a snapshot")
+	}
+
+	d.Load(
+		[]ItemBook{{Price: 99, Amount: 1}, {Price: 100, Amount: 2}},
+		[]ItemBook{{Price: 102, Amount: 1}, {Price: 101, Amount: 3}},
+	)
+	d.UpdateBid(ItemBook{Price: 99, Amount: 0})
+	d.UpdateAsk(ItemBook{Price: 103, Amount: 4})
+
+	book, ok := d.OrderBook()
+	if !ok {
+		t.Fatal("Test failed. DepthBook should be ready after a snapshot")
+	}
+
+	if len(book.Bids) != 1 || book.Bids[0].Price != 100 {
+		t.Errorf("Test failed. Unexpected bids: %+v", book.Bids)
+	}
+
+	if len(book.Asks) != 3 || book.Asks[0].Price != 101 || book.Asks[2].Price != 103 {
+		t.Errorf("Test failed. Unexpected asks: %+v", book.Asks)
+	}
+
+	d.Reset()
+	if d.IsReady() {
+		t.Error("Test failed. DepthBook should not be ready after Reset()")
+	}
+}
@@ -0,0 +1,41 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DepthError tells why the book of a pair couldn't be fetched. The cause
+// stays available to errors.Is and errors.As, e.g. a *common.HTTPError.
+type DepthError struct {
+	Exchange string
+	Pair     CurrencyPair
+	Err      error
+}
+
+func NewDepthError(exchange string, pair CurrencyPair, err error) *DepthError {
+	return &DepthError{
+		Exchange: exchange,
+		Pair:     pair,
+		Err:      err,
+	}
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("%s(%s): %s", e.Exchange, e.Pair, e.Err)
+}
+
+func (e *DepthError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports whether the deadline expired before the book arrived
+func (e *DepthError) Timeout() bool {
+	return errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// Cancelled reports whether the request was given up before its deadline
+func (e *DepthError) Cancelled() bool {
+	return errors.Is(e.Err, context.Canceled)
+}
@@ -1,12 +1,13 @@
 package exchange
 
 import (
+	"context"
 	"log"
+	"math"
 	"time"
 
 	"goarbitrage/common"
 	"goarbitrage/config"
-	"sync"
 )
 
 const (
@@ -22,42 +23,216 @@ type (
 		AuthenticatedAPISupport     bool
 		APISecret, APIKey, ClientID string
 		TakerFee, MakerFee, Fee     float64
-		Symbol                      string
+		LotStep                     float64
+		EnabledPairs                []string
+		AvailablePairs              []string
+		PairFormat                  PairFormat
+		QuoteAssets                 []string
 		APIUrl                      string
+		HTTPClient                  *common.HTTPClient
+		RateLimiters                map[EndpointClass]*RateLimiter
+		DepthTimeout                time.Duration
 	}
 
 	ItemBook struct {
-		Price     float64
-		Amount    float64
-		Timestamp float64
+		Price     float64 `json:"price"`
+		Amount    float64 `json:"amount"`
+		Timestamp float64 `json:"timestamp"`
 	}
 
+	// OrderBook is a snapshot of the book of a pair. Received is set when
+	// the bot stores the book, Timestamp is the exchange time of its newest
+	// level and zero when the exchange doesn't report one.
 	OrderBook struct {
-		Bids []ItemBook
-		Asks []ItemBook
+		Bids      []ItemBook
+		Asks      []ItemBook
+		Received  time.Time
+		Timestamp time.Time
 	}
 
+	// Balance holds the funds of a single currency, Total includes the
+	// amount reserved by open orders
+	Balance struct {
+		Currency  string
+		Available float64
+		Total     float64
+	}
+
+	// TaskResponse is a fetched book as handed to the recorder
 	TaskResponse struct {
 		Name      string
+		Symbol    string
 		OrderBook OrderBook
 	}
 
 	IBotExchange interface {
 		Setup(exch config.Exchange)
-		UpdateDepth(wg *sync.WaitGroup, done chan struct{}, resp chan TaskResponse)
+		UpdateDepth(ctx context.Context, pair CurrencyPair) (OrderBook, error)
+		GetDepthTimeout() time.Duration
+		GetPollingDelay() time.Duration
 		SetDefaults()
 		GetName() string
-		GetSymbol() string
+		GetEnabledPairs() []CurrencyPair
+		GetTakerFee() float64
+		GetMakerFee() float64
+		GetLotStep() float64
 		IsEnabled() bool
+		IsAuthenticated() bool
+		GetBalances(ctx context.Context) (map[string]Balance, error)
+		SubmitExchangeOrder(ctx context.Context, pair CurrencyPair, side OrderSide, orderType OrderType, amount, price float64) (Order, error)
+		CancelExchangeOrder(ctx context.Context, pair CurrencyPair, orderID string) (Order, error)
+		GetExchangeOrderInfo(ctx context.Context, pair CurrencyPair, orderID string) (Order, error)
 	}
 )
 
 func (e *ExchangeBase) GetName() string {
 	return e.Name
 }
-func (e *ExchangeBase) GetSymbol() string {
-	return e.Symbol
+
+// SetEnabledPairs keeps the canonical form of the configured pairs, pairs
+// that can't be parsed or are quoted in an asset the exchange doesn't
+// declare are logged and dropped
+func (e *ExchangeBase) SetEnabledPairs(pairs []string) {
+	e.EnabledPairs = nil
+	for _, i := range pairs {
+		pair, err := ParseCurrencyPair(i)
+		if err != nil {
+			log.Printf("%s: %s, pair disabled", e.Name, err)
+			continue
+		}
+
+		if len(e.QuoteAssets) > 0 && !common.StringDataCompare(e.QuoteAssets, pair.Quote) {
+			log.Printf("%s: %s isn't quoted in %s, pair disabled", e.Name, pair, common.JoinStrings(e.QuoteAssets, ", "))
+			continue
+		}
+
+		e.EnabledPairs = append(e.EnabledPairs, pair.String())
+	}
+}
+
+// GetEnabledPairs returns the pairs the exchange polls
+func (e *ExchangeBase) GetEnabledPairs() []CurrencyPair {
+	var pairs []CurrencyPair
+	for _, i := range e.EnabledPairs {
+		if pair, err := ParseCurrencyPair(i); err == nil {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
+
+// GetQuoteAssets returns the quote currencies the exchange lists, an empty
+// list means any
+func (e *ExchangeBase) GetQuoteAssets() []string {
+	return e.QuoteAssets
+}
+
+// FormatSymbol returns the native symbol of the pair on the exchange
+func (e *ExchangeBase) FormatSymbol(pair CurrencyPair) string {
+	return e.PairFormat.Format(pair)
+}
+
+// ParseSymbol returns the canonical pair of a native symbol
+func (e *ExchangeBase) ParseSymbol(symbol string) (CurrencyPair, error) {
+	return e.PairFormat.Parse(symbol)
+}
+
+func (e *ExchangeBase) GetAvailableCurrencies() []string {
+	return e.AvailablePairs
+}
+
+// UpdateAvailableCurrencies stores the products listed by the exchange and
+// logs the ones added or removed since the last update
+func (e *ExchangeBase) UpdateAvailableCurrencies(products []string) error {
+	var available []string
+	for _, i := range products {
+		available = append(available, common.StringToUpper(i))
+	}
+
+	diff := common.StringSliceDifference(e.AvailablePairs, available)
+	if len(diff) > 0 {
+		log.Printf("%s Updating available pairs. Difference: %s.\n", e.Name, diff)
+		e.AvailablePairs = available
+	}
+
+	return nil
+}
+
+// GetTakerFee returns the taker fee of the exchange in percent
+func (e *ExchangeBase) GetTakerFee() float64 {
+	return e.TakerFee
+}
+
+// GetMakerFee returns the maker fee of the exchange in percent
+func (e *ExchangeBase) GetMakerFee() float64 {
+	return e.MakerFee
+}
+
+// GetLotStep returns the minimal order amount increment of the exchange
+func (e *ExchangeBase) GetLotStep() float64 {
+	return e.LotStep
+}
+
+// SetFees overrides the default fees with the configured ones, nil keeps
+// the default while a zero fee replaces it
+func (e *ExchangeBase) SetFees(takerFee, makerFee *float64) {
+	if takerFee != nil && *takerFee >= 0 {
+		e.TakerFee = *takerFee
+	}
+	if makerFee != nil && *makerFee >= 0 {
+		e.MakerFee = *makerFee
+	}
+}
+
+// SetRateLimits overrides the request rates of the public and private
+// endpoints, zero rates keep the current limits
+func (e *ExchangeBase) SetRateLimits(public, private config.RateLimit) {
+	e.setRateLimit(ENDPOINT_PUBLIC, public)
+	e.setRateLimit(ENDPOINT_PRIVATE, private)
+}
+
+func (e *ExchangeBase) setRateLimit(class EndpointClass, limit config.RateLimit) {
+	if limit.Rate <= 0 {
+		return
+	}
+
+	if e.RateLimiters == nil {
+		e.RateLimiters = map[EndpointClass]*RateLimiter{}
+	}
+
+	e.RateLimiters[class] = NewRateLimiter(e.Name+" "+string(class), limit.Rate, limit.Burst)
+}
+
+// WaitRateLimit blocks until the endpoint class has a request to spare,
+// classes without a limit never wait. Signed requests wait before signing
+// so that nonces stay in order and timestamps fresh.
+func (e *ExchangeBase) WaitRateLimit(ctx context.Context, class EndpointClass) error {
+	return e.RateLimiters[class].Wait(ctx)
 }
+
+// SendPublicRequest fetches the JSON of a public endpoint within the public
+// rate limit
+func (e *ExchangeBase) SendPublicRequest(ctx context.Context, path string, result interface{}) error {
+	if err := e.WaitRateLimit(ctx, ENDPOINT_PUBLIC); err != nil {
+		return err
+	}
+
+	return e.GetHTTPClient().SendGetRequest(ctx, path, result)
+}
+
+// GetDepthTimeout returns the deadline for fetching a single book, zero
+// leaves it to the caller
+func (e *ExchangeBase) GetDepthTimeout() time.Duration {
+	return e.DepthTimeout
+}
+
+// GetPollingDelay returns the least time between two polls of the books of
+// the exchange
+func (e *ExchangeBase) GetPollingDelay() time.Duration {
+	return e.RESTPollingDelay
+}
+
 func (e *ExchangeBase) SetEnabled(enabled bool) {
 	e.Enabled = enabled
 }
@@ -66,6 +241,35 @@ func (e *ExchangeBase) IsEnabled() bool {
 	return e.Enabled
 }
 
+func (e *ExchangeBase) IsAuthenticated() bool {
+	return e.AuthenticatedAPISupport
+}
+
+// SetHTTPClient injects the client used for REST requests, tests hand in
+// the client of an httptest server
+func (e *ExchangeBase) SetHTTPClient(client *common.HTTPClient) {
+	e.HTTPClient = client
+}
+
+// GetHTTPClient returns the injected client or the shared default one
+func (e *ExchangeBase) GetHTTPClient() *common.HTTPClient {
+	if e.HTTPClient == nil {
+		return common.DefaultHTTPClient
+	}
+
+	return e.HTTPClient
+}
+
+// SetHTTPTimeout bounds every REST request of the exchange, connections
+// stay shared with the current client. A zero timeout keeps the default.
+func (e *ExchangeBase) SetHTTPTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	e.HTTPClient = e.GetHTTPClient().WithTimeout(timeout)
+}
+
 func (e *ExchangeBase) SetAPIKeys(APIKey, APISecret, ClientID string, b64Decode bool) {
 	e.APIKey = APIKey
 	e.ClientID = ClientID
@@ -81,3 +285,21 @@ func (e *ExchangeBase) SetAPIKeys(APIKey, APISecret, ClientID string, b64Decode
 		e.APISecret = APISecret
 	}
 }
+
+// LastUpdate returns the exchange time of the newest level of the book, zero
+// when no level carries a timestamp
+func (o OrderBook) LastUpdate() time.Time {
+	var newest float64
+	for _, levels := range [][]ItemBook{o.Bids, o.Asks} {
+		for _, i := range levels {
+			newest = math.Max(newest, i.Timestamp)
+		}
+	}
+
+	if newest == 0 {
+		return time.Time{}
+	}
+
+	sec, frac := math.Modf(newest)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second)))
+}
@@ -6,6 +6,10 @@ import (
 	"goarbitrage/config"
 )
 
+const (
+	CONFIG_TEST_FILE = "../config/testdata/config.json"
+)
+
 func TestGetName(t *testing.T) {
 	GetName := ExchangeBase{
 		Name: "TESTNAME",
@@ -17,16 +21,16 @@ func TestGetName(t *testing.T) {
 	}
 }
 
-func TestGetEnabledCurrencies(t *testing.T) {
-	enabledPairs := []string{"BTCUSD", "BTCAUD", "LTCUSD", "LTCAUD"}
-	GetEnabledCurrencies := ExchangeBase{
+func TestGetEnabledPairs(t *testing.T) {
+	enabledPairs := []string{"BTC/USD", "BTC/AUD", "LTCUSD", "LTC/AUD"}
+	GetEnabledPairs := ExchangeBase{
 		Name:         "TESTNAME",
 		EnabledPairs: enabledPairs,
 	}
 
-	enCurr := GetEnabledCurrencies.GetEnabledCurrencies()
-	if enCurr[0] != "BTCUSD" {
-		t.Error("Test Failed - Exchange GetEnabledCurrencies() incorrect string")
+	pairs := GetEnabledPairs.GetEnabledPairs()
+	if len(pairs) != 3 || pairs[0] != NewCurrencyPair("BTC", "USD") {
+		t.Error("Test Failed - Exchange GetEnabledPairs() incorrect pairs")
 	}
 }
 
@@ -53,6 +57,20 @@ func TestIsEnabled(t *testing.T) {
 	}
 }
 
+func TestSetFees(t *testing.T) {
+	SetFees := ExchangeBase{
+		Name:     "TESTNAME",
+		TakerFee: 0.25,
+		MakerFee: 0.1,
+	}
+
+	zero := 0.0
+	SetFees.SetFees(nil, &zero)
+	if SetFees.TakerFee != 0.25 || SetFees.MakerFee != 0 {
+		t.Errorf("Test Failed - Exchange SetFees() expected 0.25 and 0. Actual %v and %v", SetFees.TakerFee, SetFees.MakerFee)
+	}
+}
+
 func TestSetAPIKeys(t *testing.T) {
 	SetAPIKeys := ExchangeBase{
 		Name:    "TESTNAME",
@@ -69,7 +87,7 @@ func TestSetAPIKeys(t *testing.T) {
 
 func TestUpdateAvailableCurrencies(t *testing.T) {
 	cfg := config.GetConfig()
-	err := cfg.LoadConfig(config.CONFIG_TEST_FILE)
+	err := cfg.LoadConfig(CONFIG_TEST_FILE)
 	if err != nil {
 		t.Log("SOMETHING DONE HAPPENED!")
 	}
@@ -0,0 +1,108 @@
+// Package exchangetest serves canned exchange API responses to the adapter
+// tests, only the signature check is left to each adapter
+package exchangetest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+)
+
+type (
+	// Response is written for a route, a zero status is sent as 200
+	Response struct {
+		Status int
+		Body   string
+	}
+
+	// Verifier checks the signature of a request and returns the rejection
+	// of the exchange, nil when the request is accepted or public
+	Verifier func(r *http.Request, body []byte) *Response
+
+	// Server answers requests by method and path, e.g. "GET /accounts", and
+	// fails the test on any request without a route
+	Server struct {
+		*httptest.Server
+
+		t        *testing.T
+		verify   Verifier
+		mu       sync.Mutex
+		routes   map[string]Response
+		requests []string
+	}
+)
+
+// NewServer starts a server answering the routes with their body
+func NewServer(t *testing.T, verify Verifier, routes map[string]string) *Server {
+	s := &Server{t: t, verify: verify, routes: map[string]Response{}}
+	for route, body := range routes {
+		s.routes[route] = Response{Body: body}
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Respond sets the response of a route
+func (s *Server) Respond(route string, response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[route] = response
+}
+
+// Requests returns the method and request URI of every request received
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Attach points the REST requests of an exchange at the server
+func (s *Server) Attach(e *exchange.ExchangeBase) {
+	Attach(e, s.Server)
+}
+
+// Attach points the REST requests of an exchange at a test server
+func Attach(e *exchange.ExchangeBase, server *httptest.Server) {
+	e.APIUrl = server.URL
+	e.SetHTTPClient(common.NewHTTPClient(server.Client(), time.Second))
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	response, ok := s.routes[r.Method+" "+r.URL.Path]
+	s.mu.Unlock()
+
+	if s.verify != nil {
+		if rejection := s.verify(r, body); rejection != nil {
+			write(w, *rejection)
+			return
+		}
+	}
+
+	if !ok {
+		s.t.Errorf("Test failed. Unexpected request %s %s", r.Method, r.URL)
+		write(w, Response{Status: http.StatusNotFound})
+		return
+	}
+
+	write(w, response)
+}
+
+func write(w http.ResponseWriter, response Response) {
+	if response.Status != 0 {
+		w.WriteHeader(response.Status)
+	}
+	w.Write([]byte(response.Body))
+}
@@ -1,6 +1,7 @@
 package gemini
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -25,17 +26,37 @@ const (
 	GEMINI_ORDER_NEW    = "order/new"
 	GEMINI_ORDER_CANCEL = "order/cancel"
 	GEMINI_ORDER_STATUS = "order/status"
+	GEMINI_BALANCES     = "balances"
+)
+
+var (
+	// Gemini spells symbols in lower case without delimiter, btcusd
+	pairFormat = exchange.PairFormat{
+		Quotes: []string{"USD", "GUSD", "EUR", "GBP", "SGD", "USDT", "BTC", "ETH"},
+	}
 )
 
 type Gemini struct {
 	exchange.ExchangeBase
+	Websockets map[string]*GeminiWebsocket
+}
+
+func init() {
+	exchange.Register("Gemini", func() exchange.IBotExchange {
+		return new(Gemini)
+	})
 }
 
 func (g *Gemini) SetDefaults() {
 	g.Name = "Gemini"
 	g.Enabled = false
 	g.Verbose = false
-	g.RESTPollingDelay = 10
+	g.TakerFee = 0.25
+	g.MakerFee = 0.25
+	g.LotStep = 0.00000001
+	g.PairFormat = pairFormat
+	g.APIUrl = GEMINI_API_URL
+	g.SetRateLimits(config.RateLimit{Rate: 2, Burst: 5}, config.RateLimit{Rate: 5, Burst: 10})
 }
 
 func (g *Gemini) Setup(exch config.Exchange) {
@@ -47,26 +68,64 @@ func (g *Gemini) Setup(exch config.Exchange) {
 	g.Enabled = true
 	g.AuthenticatedAPISupport = exch.AuthenticatedAPISupport
 	g.SetAPIKeys(exch.APIKey, exch.APISecret, "", false)
-	g.RESTPollingDelay = exch.RESTPollingDelay
+	g.RESTPollingDelay = exch.RESTPollingDelay.Duration
 	g.Verbose = exch.Verbose
-	g.Symbol = exch.Symbol
+	g.SetEnabledPairs(exch.EnabledPairs)
+	g.SetFees(exch.TakerFee, exch.MakerFee)
+	g.SetHTTPTimeout(exch.HTTPTimeout.Duration)
+	g.SetRateLimits(exch.PublicRateLimit, exch.PrivateRateLimit)
+	g.DepthTimeout = exch.DepthTimeout.Duration
+	if exch.LotStep > 0 {
+		g.LotStep = exch.LotStep
+	}
+
+	if exch.Websocket {
+		// the market data feed streams a single symbol per connection
+		g.Websockets = map[string]*GeminiWebsocket{}
+		for _, pair := range g.GetEnabledPairs() {
+			ws := NewGeminiWebsocket(GEMINI_WEBSOCKET_URL, g.FormatSymbol(pair))
+			ws.Verbose = g.Verbose
+			ws.Start()
+			g.Websockets[pair.String()] = ws
+		}
+	}
 }
 
-func (g *Gemini) GetSymbols() ([]string, error) {
+// Close stops the websocket feeds, books are polled over REST afterwards
+func (g *Gemini) Close() error {
+	for _, ws := range g.Websockets {
+		ws.Close()
+	}
+	g.Websockets = nil
+
+	return nil
+}
+
+func (g *Gemini) GetSymbols() ([]exchange.CurrencyPair, error) {
 	symbols := []string{}
-	path := fmt.Sprintf("%s/v%s/%s", GEMINI_API_URL, GEMINI_API_VERSION, GEMINI_SYMBOLS)
-	err := common.SendHTTPGetRequest(path, true, &symbols)
+	path := fmt.Sprintf("%s/v%s/%s", g.APIUrl, GEMINI_API_VERSION, GEMINI_SYMBOLS)
+	err := g.SendPublicRequest(context.Background(), path, &symbols)
 	if err != nil {
 		return nil, err
 	}
-	return symbols, nil
+
+	pairs := []exchange.CurrencyPair{}
+	for _, i := range symbols {
+		pair, err := g.ParseSymbol(i)
+		if err != nil {
+			log.Warn("Gemini symbol skipped:", "warn", err.Error())
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
 }
 
-func (g *Gemini) GetOrderBook(currency string, params url.Values) (GeminiOrderBook, error) {
+func (g *Gemini) GetOrderBook(ctx context.Context, currency string, params url.Values) (GeminiOrderBook, error) {
 	var response GeminiOrderBook
-	path := common.EncodeURLValues(fmt.Sprintf("%s/v%s/%s/%s", GEMINI_API_URL, GEMINI_API_VERSION, GEMINI_ORDERBOOK, currency), params)
+	path := common.EncodeURLValues(fmt.Sprintf("%s/v%s/%s/%s", g.APIUrl, GEMINI_API_VERSION, GEMINI_ORDERBOOK, currency), params)
 
-	err := common.SendHTTPGetRequest(path, true, &response)
+	err := g.SendPublicRequest(ctx, path, &response)
 	if err != nil {
 		return response, err
 	}
@@ -74,7 +133,7 @@ func (g *Gemini) GetOrderBook(currency string, params url.Values) (GeminiOrderBo
 	return response, nil
 }
 
-func (g *Gemini) NewOrder(symbol string, amount, price float64, side, orderType string) (int64, error) {
+func (g *Gemini) NewOrder(ctx context.Context, symbol string, amount, price float64, side, orderType string) (GeminiOrder, error) {
 	request := make(map[string]interface{})
 	request["symbol"] = symbol
 	request["amount"] = strconv.FormatFloat(amount, 'f', -1, 64)
@@ -83,31 +142,31 @@ func (g *Gemini) NewOrder(symbol string, amount, price float64, side, orderType
 	request["type"] = orderType
 
 	response := GeminiOrder{}
-	err := g.SendAuthenticatedHTTPRequest("POST", GEMINI_ORDER_NEW, request, &response)
+	err := g.SendAuthenticatedHTTPRequest(ctx, "POST", GEMINI_ORDER_NEW, request, &response)
 	if err != nil {
-		return 0, err
+		return GeminiOrder{}, err
 	}
-	return response.OrderID, nil
+	return response, nil
 }
 
-func (g *Gemini) CancelOrder(OrderID int64) (GeminiOrder, error) {
+func (g *Gemini) CancelOrder(ctx context.Context, OrderID int64) (GeminiOrder, error) {
 	request := make(map[string]interface{})
 	request["order_id"] = OrderID
 
 	response := GeminiOrder{}
-	err := g.SendAuthenticatedHTTPRequest("POST", GEMINI_ORDER_CANCEL, request, &response)
+	err := g.SendAuthenticatedHTTPRequest(ctx, "POST", GEMINI_ORDER_CANCEL, request, &response)
 	if err != nil {
 		return GeminiOrder{}, err
 	}
 	return response, nil
 }
 
-func (g *Gemini) GetOrderStatus(orderID int64) (GeminiOrder, error) {
+func (g *Gemini) GetOrderStatus(ctx context.Context, orderID int64) (GeminiOrder, error) {
 	request := make(map[string]interface{})
 	request["order_id"] = orderID
 
 	response := GeminiOrder{}
-	err := g.SendAuthenticatedHTTPRequest("POST", GEMINI_ORDER_STATUS, request, &response)
+	err := g.SendAuthenticatedHTTPRequest(ctx, "POST", GEMINI_ORDER_STATUS, request, &response)
 	if err != nil {
 		return GeminiOrder{}, err
 	}
@@ -115,9 +174,9 @@ func (g *Gemini) GetOrderStatus(orderID int64) (GeminiOrder, error) {
 	return response, nil
 }
 
-func (g *Gemini) GetOrders() ([]GeminiOrder, error) {
+func (g *Gemini) GetOrders(ctx context.Context) ([]GeminiOrder, error) {
 	response := []GeminiOrder{}
-	err := g.SendAuthenticatedHTTPRequest("POST", GEMINI_ORDERS, nil, &response)
+	err := g.SendAuthenticatedHTTPRequest(ctx, "POST", GEMINI_ORDERS, nil, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +184,25 @@ func (g *Gemini) GetOrders() ([]GeminiOrder, error) {
 	return response, nil
 }
 
-func (g *Gemini) SendAuthenticatedHTTPRequest(method, path string, params map[string]interface{}, result interface{}) (err error) {
+func (g *Gemini) GetAccountBalances(ctx context.Context) ([]GeminiBalance, error) {
+	response := []GeminiBalance{}
+	err := g.SendAuthenticatedHTTPRequest(ctx, "POST", GEMINI_BALANCES, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (g *Gemini) SendAuthenticatedHTTPRequest(ctx context.Context, method, path string, params map[string]interface{}, result interface{}) (err error) {
+	if len(g.APIKey) == 0 {
+		return errors.New("SendAuthenticatedHTTPRequest: Invalid API key")
+	}
+
+	if err := g.WaitRateLimit(ctx, exchange.ENDPOINT_PRIVATE); err != nil {
+		return err
+	}
+
 	request := make(map[string]interface{})
 	request["request"] = fmt.Sprintf("/v%s/%s", GEMINI_API_VERSION, path)
 	request["nonce"] = time.Now().UnixNano()
@@ -152,11 +229,21 @@ func (g *Gemini) SendAuthenticatedHTTPRequest(method, path string, params map[st
 	headers["X-GEMINI-PAYLOAD"] = PayloadBase64
 	headers["X-GEMINI-SIGNATURE"] = common.HexEncodeToString(hmac)
 
-	resp, err := common.SendHTTPRequest(method, GEMINI_API_URL+path, headers, strings.NewReader(""))
+	endpoint := fmt.Sprintf("%s/v%s/%s", g.APIUrl, GEMINI_API_VERSION, path)
+	resp, err := g.GetHTTPClient().SendRequest(ctx, method, endpoint, headers, strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+
 	if g.Verbose {
 		log.Info("Recieved raw:", "info", resp)
 	}
 
+	errResponse := GeminiErrorResponse{}
+	if common.JSONDecode([]byte(resp), &errResponse) == nil && errResponse.Result == "error" {
+		return fmt.Errorf("SendAuthenticatedHTTPRequest: %s: %s", errResponse.Reason, errResponse.Message)
+	}
+
 	err = common.JSONDecode([]byte(resp), &result)
 	if err != nil {
 		return errors.New("Unable to JSON Unmarshal response.")
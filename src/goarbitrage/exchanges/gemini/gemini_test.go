@@ -0,0 +1,44 @@
+package gemini
+
+import (
+	"context"
+	"testing"
+
+	"goarbitrage/exchanges"
+)
+
+func TestOrderFromGemini(t *testing.T) {
+	order := orderFromGemini(GeminiOrder{
+		OrderID:           44375901,
+		Symbol:            "btcusd",
+		Price:             400,
+		AvgExecutionPrice: 0,
+		Side:              "sell",
+		Type:              "exchange limit",
+		IsCancelled:       true,
+		OriginalAmount:    3,
+		RemainingAmount:   3,
+	})
+
+	if order.ID != "44375901" || order.Symbol != "BTC/USD" {
+		t.Errorf("Test failed. Unexpected id or symbol: %+v", order)
+	}
+
+	if order.Side != exchange.SideSell || order.Type != exchange.OrderTypeLimit || order.Price != 400 {
+		t.Errorf("Test failed. Unexpected side, type or price: %+v", order)
+	}
+
+	if order.Status != exchange.OrderStatusCancelled || !order.IsClosed() {
+		t.Errorf("Test failed. Unexpected status: %+v", order)
+	}
+}
+
+func TestSubmitExchangeOrderMarket(t *testing.T) {
+	g := Gemini{}
+	g.SetDefaults()
+
+	_, err := g.SubmitExchangeOrder(context.Background(), exchange.NewCurrencyPair("BTC", "USD"), exchange.SideBuy, exchange.OrderTypeMarket, 1, 0)
+	if err == nil {
+		t.Error("Test failed. Gemini should reject market orders")
+	}
+}
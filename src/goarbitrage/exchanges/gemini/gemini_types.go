@@ -12,6 +12,12 @@ type (
 		Asks []GeminiOrderbookEntry `json:"asks"`
 	}
 
+	GeminiErrorResponse struct {
+		Result  string `json:"result"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+
 	GeminiOrder struct {
 		OrderID           int64   `json:"order_id"`
 		ClientOrderID     string  `json:"client_order_id"`
@@ -30,4 +36,30 @@ type (
 		RemainingAmount   float64 `json:"remaining_amount,string"`
 		OriginalAmount    float64 `json:"original_amount,string"`
 	}
+
+	GeminiBalance struct {
+		Type                   string  `json:"type"`
+		Currency               string  `json:"currency"`
+		Amount                 float64 `json:"amount,string"`
+		Available              float64 `json:"available,string"`
+		AvailableForWithdrawal float64 `json:"availableForWithdrawal,string"`
+	}
+
+	GeminiWebsocketEvent struct {
+		Type      string  `json:"type"`
+		Reason    string  `json:"reason"`
+		Side      string  `json:"side"`
+		Price     float64 `json:"price,string"`
+		Delta     float64 `json:"delta,string"`
+		Remaining float64 `json:"remaining,string"`
+	}
+
+	GeminiWebsocketMessage struct {
+		Type           string                 `json:"type"`
+		EventID        int64                  `json:"eventId"`
+		SocketSequence int64                  `json:"socket_sequence"`
+		Timestamp      int64                  `json:"timestamp"`
+		TimestampMS    int64                  `json:"timestampms"`
+		Events         []GeminiWebsocketEvent `json:"events"`
+	}
 )
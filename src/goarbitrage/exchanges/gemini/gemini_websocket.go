@@ -0,0 +1,184 @@
+package gemini
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+)
+
+const (
+	GEMINI_WEBSOCKET_URL       = "wss://api.gemini.com/v1/marketdata/"
+	GEMINI_WEBSOCKET_TIMEOUT   = 30 * time.Second
+	GEMINI_WEBSOCKET_RECONNECT = 5 * time.Second
+)
+
+// GeminiWebsocket keeps a local order book from the market data feed. The
+// feed starts with a snapshot of the book, a gap in the socket sequence
+// makes it reconnect to get a fresh one.
+type GeminiWebsocket struct {
+	URL            string
+	Symbol         string
+	Verbose        bool
+	ReconnectDelay time.Duration
+	Book           *exchange.DepthBook
+
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	sequence int64
+	done     chan struct{}
+}
+
+func NewGeminiWebsocket(url, symbol string) *GeminiWebsocket {
+	return &GeminiWebsocket{
+		URL:            url,
+		Symbol:         symbol,
+		ReconnectDelay: GEMINI_WEBSOCKET_RECONNECT,
+		Book:           exchange.NewDepthBook(),
+		done:           make(chan struct{}),
+	}
+}
+
+// Start connects in background and keeps reconnecting until Close
+func (w *GeminiWebsocket) Start() {
+	go w.run()
+}
+
+func (w *GeminiWebsocket) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return
+	default:
+		close(w.done)
+	}
+
+	if w.conn != nil {
+		w.conn.Close()
+	}
+}
+
+func (w *GeminiWebsocket) run() {
+	for {
+		err := w.connect()
+		w.Book.Reset()
+
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+
+		log.Warn("Gemini websocket disconnected, falling back to REST", "warn", fmt.Sprint(err))
+
+		select {
+		case <-w.done:
+			return
+		case <-time.After(w.ReconnectDelay):
+		}
+	}
+}
+
+func (w *GeminiWebsocket) connect() error {
+	dialer := websocket.Dialer{HandshakeTimeout: GEMINI_WEBSOCKET_TIMEOUT}
+	conn, _, err := dialer.Dial(w.URL+common.StringToUpper(w.Symbol), nil)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	select {
+	case <-w.done:
+		w.mu.Unlock()
+		conn.Close()
+		return nil
+	default:
+	}
+	w.conn = conn
+	w.sequence = -1
+	w.mu.Unlock()
+
+	defer conn.Close()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(GEMINI_WEBSOCKET_TIMEOUT))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if w.Verbose {
+			log.Info("Gemini websocket recieved:", "info", string(data))
+		}
+
+		if err := w.handleMessage(data); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *GeminiWebsocket) handleMessage(data []byte) error {
+	var message GeminiWebsocketMessage
+	if err := common.JSONDecode(data, &message); err != nil {
+		return err
+	}
+
+	if message.SocketSequence != w.sequence+1 {
+		return fmt.Errorf("Gemini websocket: sequence gap, expected %d got %d", w.sequence+1, message.SocketSequence)
+	}
+	w.sequence = message.SocketSequence
+
+	if message.Type != "update" {
+		return nil
+	}
+
+	timestamp := float64(message.TimestampMS) / 1000
+
+	if !w.Book.IsReady() {
+		var (
+			bids, asks []exchange.ItemBook
+			initial    bool
+		)
+
+		for _, i := range message.Events {
+			if i.Type != "change" || i.Reason != "initial" {
+				continue
+			}
+			initial = true
+
+			item := exchange.ItemBook{Price: i.Price, Amount: i.Remaining, Timestamp: timestamp}
+			if i.Side == "bid" {
+				bids = append(bids, item)
+			} else {
+				asks = append(asks, item)
+			}
+		}
+
+		if initial {
+			w.Book.Load(bids, asks)
+		}
+		return nil
+	}
+
+	for _, i := range message.Events {
+		if i.Type != "change" {
+			continue
+		}
+
+		item := exchange.ItemBook{Price: i.Price, Amount: i.Remaining, Timestamp: timestamp}
+		if i.Side == "bid" {
+			w.Book.UpdateBid(item)
+		} else {
+			w.Book.UpdateAsk(item)
+		}
+	}
+
+	return nil
+}
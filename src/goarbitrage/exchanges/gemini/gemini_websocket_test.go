@@ -0,0 +1,130 @@
+package gemini
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"goarbitrage/exchanges"
+)
+
+const (
+	testSnapshot = `{"type":"update","eventId":1,"socket_sequence":0,"events":[` +
+		`{"type":"change","reason":"initial","price":"100.00","delta":"1","remaining":"1","side":"bid"},` +
+		`{"type":"change","reason":"initial","price":"101.00","delta":"2","remaining":"2","side":"ask"}]}`
+	testUpdate = `{"type":"update","eventId":2,"timestamp":1500000000,"timestampms":1500000000250,"socket_sequence":2,"events":[` +
+		`{"type":"trade","tid":5,"price":"101.00","amount":"0.5","makerSide":"ask"},` +
+		`{"type":"change","reason":"trade","price":"101.00","delta":"-0.5","remaining":"1.5","side":"ask"},` +
+		`{"type":"change","reason":"place","price":"100.50","delta":"3","remaining":"3","side":"bid"},` +
+		`{"type":"change","reason":"cancel","price":"100.00","delta":"-1","remaining":"0","side":"bid"}]}`
+)
+
+func TestWebsocketUpdates(t *testing.T) {
+	w := NewGeminiWebsocket("", "BTCUSD")
+	w.sequence = -1
+
+	for _, i := range []string{testSnapshot, `{"type":"heartbeat","socket_sequence":1}`, testUpdate} {
+		if err := w.handleMessage([]byte(i)); err != nil {
+			t.Fatalf("Test failed. handleMessage() error: %s", err)
+		}
+	}
+
+	book, ok := w.Book.OrderBook()
+	if !ok {
+		t.Fatal("Test failed. Book should be ready")
+	}
+
+	if len(book.Bids) != 1 || book.Bids[0].Price != 100.5 || book.Bids[0].Timestamp != 1500000000.25 {
+		t.Errorf("Test failed. Unexpected bids: %+v", book.Bids)
+	}
+
+	if len(book.Asks) != 1 || book.Asks[0].Amount != 1.5 || book.Asks[0].Timestamp != 1500000000.25 {
+		t.Errorf("Test failed. Unexpected asks: %+v", book.Asks)
+	}
+}
+
+func TestWebsocketSequenceGap(t *testing.T) {
+	w := NewGeminiWebsocket("", "BTCUSD")
+	w.sequence = -1
+
+	if err := w.handleMessage([]byte(testSnapshot)); err != nil || !w.Book.IsReady() {
+		t.Fatalf("Test failed. Snapshot not loaded: %v", err)
+	}
+
+	if err := w.handleMessage([]byte(`{"type":"heartbeat","socket_sequence":2}`)); err == nil {
+		t.Error("Test failed. Expected an error on sequence gap")
+	}
+}
+
+func TestWebsocketReconnect(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+
+	sessions := [][]string{
+		// sequence 1 is lost, the client must reconnect and resnapshot
+		{testSnapshot, testUpdate},
+		{`{"type":"update","eventId":10,"socket_sequence":0,"events":[` +
+			`{"type":"change","reason":"initial","price":"99.00","delta":"7","remaining":"7","side":"bid"},` +
+			`{"type":"change","reason":"initial","price":"102.00","delta":"4","remaining":"4","side":"ask"}]}`},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Log(err)
+			return
+		}
+		defer conn.Close()
+
+		mu.Lock()
+		session := len(paths)
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		if session >= len(sessions) {
+			return
+		}
+
+		for _, i := range sessions[session] {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(i)); err != nil {
+				return
+			}
+		}
+
+		conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	w := NewGeminiWebsocket("ws"+strings.TrimPrefix(server.URL, "http")+"/v1/marketdata/", "btcusd")
+	w.ReconnectDelay = 10 * time.Millisecond
+	w.Start()
+	defer w.Close()
+
+	var book exchange.OrderBook
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		var ok bool
+		if book, ok = w.Book.OrderBook(); ok && len(book.Bids) > 0 && book.Bids[0].Price == 99 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(book.Bids) != 1 || book.Bids[0].Amount != 7 || len(book.Asks) != 1 || book.Asks[0].Price != 102 {
+		t.Fatalf("Test failed. Book not resnapshotted: %+v", book)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) < 2 || paths[0] != "/v1/marketdata/BTCUSD" {
+		t.Errorf("Test failed. Unexpected connections: %v", paths)
+	}
+}
@@ -1,53 +1,142 @@
 package gemini
 
 import (
+	"context"
 	"fmt"
-	"net/url"
-	"sync"
+	"strconv"
 
 	"github.com/mgutz/logxi/v1"
 
+	"goarbitrage/common"
 	"goarbitrage/exchanges"
 )
 
-func (g *Gemini) UpdateDepth(wg *sync.WaitGroup, done chan struct{}, resp chan exchange.TaskResponse) {
-	defer wg.Done()
-	var (
-		values url.Values
-	)
-
+func (g *Gemini) UpdateDepth(ctx context.Context, pair exchange.CurrencyPair) (exchange.OrderBook, error) {
+	symbol := g.FormatSymbol(pair)
 	if g.Verbose {
-		log.Info(fmt.Sprintf("%s polling delay: %ds.\n", g.GetName(), g.RESTPollingDelay))
-		log.Info(fmt.Sprintf("%s currencies enabled: %s.\n", g.GetName(), g.Symbol))
-	}
-
-	for {
-		select {
-		case _, ok := <-done:
-			if !ok {
-				return
-			}
-		default:
-			book, err := g.GetOrderBook(g.GetSymbol(), values)
-			if err != nil {
-				log.Error(fmt.Sprintf("Error get order book %s(%s)", g.GetName(), g.Symbol), "error", err.Error())
-				return
-			}
-
-			var t exchange.OrderBook
-			for _, i := range book.Bids {
-				t.Bids = append(t.Bids, exchange.ItemBook(i))
-			}
-			for _, i := range book.Asks {
-				t.Asks = append(t.Asks, exchange.ItemBook(i))
-			}
-
-			resp <- exchange.TaskResponse{
-				Name:      g.Name,
-				OrderBook: t,
-			}
-
-			return
+		log.Info(fmt.Sprintf("%s polling %s", g.GetName(), symbol))
+	}
+
+	if ws, ok := g.Websockets[pair.String()]; ok {
+		if book, ok := ws.Book.OrderBook(); ok {
+			return book, nil
+		}
+
+		log.Warn(fmt.Sprintf("%s(%s) websocket book not ready, polling REST", g.GetName(), symbol), "warn")
+	}
+
+	book, err := g.GetOrderBook(ctx, symbol, nil)
+	if err != nil {
+		return exchange.OrderBook{}, err
+	}
+
+	var t exchange.OrderBook
+	for _, i := range book.Bids {
+		t.Bids = append(t.Bids, exchange.ItemBook(i))
+	}
+	for _, i := range book.Asks {
+		t.Asks = append(t.Asks, exchange.ItemBook(i))
+	}
+
+	return t, nil
+}
+
+func (g *Gemini) SubmitExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, side exchange.OrderSide, orderType exchange.OrderType, amount, price float64) (exchange.Order, error) {
+	// Gemini only supports limit orders
+	if orderType != exchange.OrderTypeLimit {
+		return exchange.Order{}, fmt.Errorf("%s: unsupported order type %s", g.Name, orderType)
+	}
+
+	order, err := g.NewOrder(ctx, g.FormatSymbol(pair), amount, price, string(side), "exchange limit")
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromGemini(order), nil
+}
+
+func (g *Gemini) CancelExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("%s: invalid order id %s", g.Name, orderID)
+	}
+
+	order, err := g.CancelOrder(ctx, id)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromGemini(order), nil
+}
+
+func (g *Gemini) GetExchangeOrderInfo(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("%s: invalid order id %s", g.Name, orderID)
+	}
+
+	order, err := g.GetOrderStatus(ctx, id)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return orderFromGemini(order), nil
+}
+
+// GetOpenOrders returns the live orders of the account
+func (g *Gemini) GetOpenOrders(ctx context.Context) ([]exchange.Order, error) {
+	response, err := g.GetOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []exchange.Order
+	for _, o := range response {
+		orders = append(orders, orderFromGemini(o))
+	}
+
+	return orders, nil
+}
+
+func (g *Gemini) GetBalances(ctx context.Context) (map[string]exchange.Balance, error) {
+	balances, err := g.GetAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]exchange.Balance{}
+	for _, i := range balances {
+		currency := common.StringToUpper(i.Currency)
+		result[currency] = exchange.Balance{
+			Currency:  currency,
+			Available: i.Available,
+			Total:     i.Amount,
 		}
 	}
+
+	return result, nil
+}
+
+func orderFromGemini(o GeminiOrder) exchange.Order {
+	orderType := exchange.OrderTypeLimit
+	if common.StringContains(o.Type, "market") {
+		orderType = exchange.OrderTypeMarket
+	}
+
+	symbol := common.StringToUpper(o.Symbol)
+	if pair, err := pairFormat.Parse(o.Symbol); err == nil {
+		symbol = pair.String()
+	}
+
+	return exchange.Order{
+		ID:           strconv.FormatInt(o.OrderID, 10),
+		Symbol:       symbol,
+		Side:         exchange.OrderSide(o.Side),
+		Type:         orderType,
+		Price:        o.Price,
+		Amount:       o.OriginalAmount,
+		FilledAmount: o.ExecutedAmount,
+		AvgPrice:     o.AvgExecutionPrice,
+		Status:       exchange.OrderStatusFromState(o.IsLive, o.IsCancelled, o.OriginalAmount, o.ExecutedAmount),
+	}
 }
@@ -0,0 +1,296 @@
+package kraken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/config"
+	"goarbitrage/exchanges"
+)
+
+const (
+	KRAKEN_API_URL     = "https://api.kraken.com"
+	KRAKEN_API_VERSION = "0"
+
+	KRAKEN_DEPTH          = "Depth"
+	KRAKEN_ASSET_PAIRS    = "AssetPairs"
+	KRAKEN_BALANCE        = "Balance"
+	KRAKEN_ORDER_NEW      = "AddOrder"
+	KRAKEN_ORDER_CANCEL   = "CancelOrder"
+	KRAKEN_ORDER_STATUS   = "QueryOrders"
+	KRAKEN_OPEN_ORDERS    = "OpenOrders"
+	KRAKEN_DEPTH_COUNT    = 25
+	KRAKEN_PUBLIC_PATH    = "public"
+	KRAKEN_PRIVATE_PATH   = "private"
+	KRAKEN_CONTENT_TYPE   = "application/x-www-form-urlencoded"
+	KRAKEN_ASSET_PREFIXES = "XZ"
+)
+
+var (
+	// Kraken requests take the XBTUSD altname, books and balances come back
+	// with the legacy XXBTZUSD asset names
+	pairFormat = exchange.PairFormat{
+		Uppercase: true,
+		Aliases:   map[string]string{"BTC": "XBT", "DOGE": "XDG"},
+		Quotes:    []string{"USD", "EUR", "GBP", "CAD", "JPY", "CHF", "USDT", "BTC", "ETH"},
+	}
+
+	assetAliases = map[string]string{
+		"XBT": "BTC",
+		"XDG": "DOGE",
+	}
+)
+
+type Kraken struct {
+	exchange.ExchangeBase
+}
+
+func init() {
+	exchange.Register("Kraken", func() exchange.IBotExchange {
+		return new(Kraken)
+	})
+}
+
+func (k *Kraken) SetDefaults() {
+	k.Name = "Kraken"
+	k.Enabled = false
+	k.Verbose = false
+	k.TakerFee = 0.26
+	k.MakerFee = 0.16
+	k.LotStep = 0.00000001
+	k.PairFormat = pairFormat
+	k.APIUrl = KRAKEN_API_URL
+	k.SetRateLimits(config.RateLimit{Rate: 1, Burst: 5}, config.RateLimit{Rate: 0.33, Burst: 15})
+}
+
+func (k *Kraken) Setup(exch config.Exchange) {
+	if !exch.Enabled {
+		k.SetEnabled(false)
+		return
+	}
+
+	k.Enabled = true
+	k.AuthenticatedAPISupport = exch.AuthenticatedAPISupport
+	// Kraken hands out the private key base64 encoded
+	k.SetAPIKeys(exch.APIKey, exch.APISecret, "", true)
+	k.RESTPollingDelay = exch.RESTPollingDelay.Duration
+	k.Verbose = exch.Verbose
+	k.SetEnabledPairs(exch.EnabledPairs)
+	k.SetFees(exch.TakerFee, exch.MakerFee)
+	k.SetHTTPTimeout(exch.HTTPTimeout.Duration)
+	k.SetRateLimits(exch.PublicRateLimit, exch.PrivateRateLimit)
+	k.DepthTimeout = exch.DepthTimeout.Duration
+	if exch.LotStep > 0 {
+		k.LotStep = exch.LotStep
+	}
+}
+
+// GetOrderBook returns the depth of the native pair, Kraken keys the result
+// by its own name of the pair so the only entry is taken
+func (k *Kraken) GetOrderBook(ctx context.Context, symbol string, count int) (KrakenOrderBook, error) {
+	values := url.Values{}
+	values.Set("pair", symbol)
+	if count > 0 {
+		values.Set("count", strconv.Itoa(count))
+	}
+
+	result := map[string]KrakenOrderBook{}
+	err := k.SendHTTPGetRequest(ctx, KRAKEN_DEPTH, values, &result)
+	if err != nil {
+		return KrakenOrderBook{}, err
+	}
+
+	for _, book := range result {
+		return book, nil
+	}
+
+	return KrakenOrderBook{}, fmt.Errorf("%s: no order book for %s", k.Name, symbol)
+}
+
+func (k *Kraken) GetSymbols() ([]exchange.CurrencyPair, error) {
+	result := map[string]KrakenAssetPair{}
+	err := k.SendHTTPGetRequest(context.Background(), KRAKEN_ASSET_PAIRS, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []exchange.CurrencyPair{}
+	for name, i := range result {
+		// dark pool books duplicate the regular ones
+		if strings.HasSuffix(name, ".d") {
+			continue
+		}
+
+		pairs = append(pairs, exchange.NewCurrencyPair(AssetFromKraken(i.Base), AssetFromKraken(i.Quote)))
+	}
+	return pairs, nil
+}
+
+func (k *Kraken) GetAccountBalances(ctx context.Context) (map[string]float64, error) {
+	result := map[string]string{}
+	err := k.SendAuthenticatedHTTPRequest(ctx, KRAKEN_BALANCE, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := map[string]float64{}
+	for asset, amount := range result {
+		value, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid balance %s of %s", k.Name, amount, asset)
+		}
+		balances[asset] = value
+	}
+
+	return balances, nil
+}
+
+func (k *Kraken) NewOrder(ctx context.Context, symbol, side, orderType string, volume, price float64) (KrakenAddOrderResponse, error) {
+	values := url.Values{}
+	values.Set("pair", symbol)
+	values.Set("type", side)
+	values.Set("ordertype", orderType)
+	values.Set("volume", strconv.FormatFloat(volume, 'f', -1, 64))
+	if orderType == "limit" {
+		values.Set("price", strconv.FormatFloat(price, 'f', -1, 64))
+	}
+
+	response := KrakenAddOrderResponse{}
+	err := k.SendAuthenticatedHTTPRequest(ctx, KRAKEN_ORDER_NEW, values, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (k *Kraken) CancelOrder(ctx context.Context, txid string) (KrakenCancelOrderResponse, error) {
+	values := url.Values{}
+	values.Set("txid", txid)
+
+	response := KrakenCancelOrderResponse{}
+	err := k.SendAuthenticatedHTTPRequest(ctx, KRAKEN_ORDER_CANCEL, values, &response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (k *Kraken) QueryOrders(ctx context.Context, txids ...string) (map[string]KrakenOrder, error) {
+	values := url.Values{}
+	values.Set("txid", strings.Join(txids, ","))
+
+	response := map[string]KrakenOrder{}
+	err := k.SendAuthenticatedHTTPRequest(ctx, KRAKEN_ORDER_STATUS, values, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetOpenOrdersByID lists the open orders of the account keyed by their txid
+func (k *Kraken) GetOpenOrdersByID(ctx context.Context) (map[string]KrakenOrder, error) {
+	response := KrakenOpenOrdersResponse{}
+	err := k.SendAuthenticatedHTTPRequest(ctx, KRAKEN_OPEN_ORDERS, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Open, nil
+}
+
+func (k *Kraken) SendHTTPGetRequest(ctx context.Context, method string, values url.Values, result interface{}) error {
+	path := common.EncodeURLValues(fmt.Sprintf("%s/%s/%s/%s", k.APIUrl, KRAKEN_API_VERSION, KRAKEN_PUBLIC_PATH, method), values)
+
+	response := KrakenResponse{}
+	err := k.SendPublicRequest(ctx, path, &response)
+	if err != nil {
+		return err
+	}
+
+	return response.decode(result)
+}
+
+// SendAuthenticatedHTTPRequest signs the form with the base64 decoded secret:
+// HMAC-SHA512 of the URI path followed by SHA256 of nonce and post data
+func (k *Kraken) SendAuthenticatedHTTPRequest(ctx context.Context, method string, values url.Values, result interface{}) error {
+	if len(k.APIKey) == 0 {
+		return errors.New("SendAuthenticatedHTTPRequest: Invalid API key")
+	}
+
+	if err := k.WaitRateLimit(ctx, exchange.ENDPOINT_PRIVATE); err != nil {
+		return err
+	}
+
+	if values == nil {
+		values = url.Values{}
+	}
+
+	nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
+	values.Set("nonce", nonce)
+
+	path := fmt.Sprintf("/%s/%s/%s", KRAKEN_API_VERSION, KRAKEN_PRIVATE_PATH, method)
+	payload := values.Encode()
+
+	shasum := common.GetSHA256([]byte(nonce + payload))
+	signature := common.Base64Encode(common.GetHMAC(common.HASH_SHA512, append([]byte(path), shasum...), []byte(k.APISecret)))
+
+	if k.Verbose {
+		log.Info("Request:", "info", path+" "+payload)
+	}
+
+	headers := make(map[string]string)
+	headers["API-Key"] = k.APIKey
+	headers["API-Sign"] = signature
+	headers["Content-Type"] = KRAKEN_CONTENT_TYPE
+
+	resp, err := k.GetHTTPClient().SendRequest(ctx, "POST", k.APIUrl+path, headers, strings.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	if k.Verbose {
+		log.Info("Recieved raw:", "info", resp)
+	}
+
+	response := KrakenResponse{}
+	err = common.JSONDecode([]byte(resp), &response)
+	if err != nil {
+		return errors.New("Unable to JSON Unmarshal response.")
+	}
+
+	return response.decode(result)
+}
+
+func (r KrakenResponse) decode(result interface{}) error {
+	if len(r.Error) > 0 {
+		return fmt.Errorf("Kraken API error: %s", strings.Join(r.Error, ", "))
+	}
+
+	return common.JSONDecode(r.Result, result)
+}
+
+// AssetFromKraken returns the canonical code of a Kraken asset, legacy
+// four letter names carry an X (crypto) or Z (fiat) prefix: XXBT, ZUSD
+func AssetFromKraken(asset string) string {
+	asset = common.StringToUpper(asset)
+	if len(asset) == 4 && strings.ContainsAny(asset[:1], KRAKEN_ASSET_PREFIXES) {
+		asset = asset[1:]
+	}
+
+	if canonical, ok := assetAliases[asset]; ok {
+		return canonical
+	}
+
+	return asset
+}
@@ -0,0 +1,181 @@
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+	"goarbitrage/exchanges/exchangetest"
+)
+
+const (
+	testSecret = "c2VjcmV0LWtleQ=="
+)
+
+// verify answers like Kraken to private requests which are not signed
+// with testSecret
+func verify(r *http.Request, body []byte) *exchangetest.Response {
+	if r.Header.Get("API-Key") == "" {
+		return nil
+	}
+
+	form, _ := url.ParseQuery(string(body))
+	secret, _ := base64.StdEncoding.DecodeString(testSecret)
+	shasum := sha256.Sum256([]byte(form.Get("nonce") + string(body)))
+	mac := hmac.New(sha512.New, secret)
+	mac.Write(append([]byte(r.URL.Path), shasum[:]...))
+
+	if r.Header.Get("API-Key") != "key" || r.Header.Get("API-Sign") != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+		return &exchangetest.Response{Body: `{"error":["EAPI:Invalid signature"]}`}
+	}
+
+	return nil
+}
+
+func newTestKraken() *Kraken {
+	k := &Kraken{}
+	k.SetDefaults()
+	k.EnabledPairs = []string{"BTC/USD"}
+	k.SetAPIKeys("key", testSecret, "", true)
+	return k
+}
+
+func TestUpdateDepth(t *testing.T) {
+	server := exchangetest.NewServer(t, verify, map[string]string{
+		"GET /0/public/Depth": `{"error":[],"result":{"XXBTZUSD":{
+			"asks":[["1001.50000","0.500",1493640000],["1002.00000","1.250",1493640001]],
+			"bids":[["1000.10000","2.000",1493640002]]}}}`,
+	})
+	defer server.Close()
+
+	k := newTestKraken()
+	server.Attach(&k.ExchangeBase)
+
+	book, err := k.UpdateDepth(context.Background(), exchange.NewCurrencyPair("BTC", "USD"))
+	if err != nil {
+		t.Fatalf("Test failed. UpdateDepth() error: %s", err)
+	}
+
+	if requests := server.Requests(); len(requests) != 1 || !common.StringContains(requests[0], "pair=XBTUSD") {
+		t.Errorf("Test failed. Expected the XBTUSD book to be requested. Actual %v", requests)
+	}
+
+	if len(book.Asks) != 2 || len(book.Bids) != 1 {
+		t.Fatalf("Test failed. Unexpected book: %+v", book)
+	}
+
+	if book.Asks[0].Price != 1001.5 || book.Asks[0].Amount != 0.5 || book.Asks[0].Timestamp != 1493640000 {
+		t.Errorf("Test failed. Unexpected ask: %+v", book.Asks[0])
+	}
+
+	if book.Bids[0].Price != 1000.1 || book.Bids[0].Amount != 2 {
+		t.Errorf("Test failed. Unexpected bid: %+v", book.Bids[0])
+	}
+}
+
+func TestUpdateDepthDeadline(t *testing.T) {
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	defer server.Close()
+
+	k := newTestKraken()
+	exchangetest.Attach(&k.ExchangeBase, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := k.UpdateDepth(ctx, exchange.NewCurrencyPair("BTC", "USD"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Test failed. Expected %s. Actual %v", context.DeadlineExceeded, err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("Test failed. Expected the request to be cancelled on the server")
+	}
+}
+
+func TestSignedRequests(t *testing.T) {
+	server := exchangetest.NewServer(t, verify, map[string]string{
+		"POST /0/private/Balance": `{"error":[],"result":{"XXBT":"0.5000000000","ZUSD":"1250.7500","XETH":"0.0000000000"}}`,
+		"GET /0/public/Depth":     `{"error":["EQuery:Unknown asset pair"]}`,
+	})
+	defer server.Close()
+
+	k := newTestKraken()
+	server.Attach(&k.ExchangeBase)
+
+	balances, err := k.GetBalances(context.Background())
+	if err != nil {
+		t.Fatalf("Test failed. GetBalances() error: %s", err)
+	}
+
+	if balances["BTC"].Available != 0.5 || balances["USD"].Total != 1250.75 {
+		t.Errorf("Test failed. Unexpected balances: %+v", balances)
+	}
+
+	if _, ok := balances["XXBT"]; ok {
+		t.Error("Test failed. Legacy asset names should be mapped")
+	}
+
+	_, err = k.GetOrderBook(context.Background(), "FOOBAR", 0)
+	if err == nil || err.Error() != "Kraken API error: EQuery:Unknown asset pair" {
+		t.Errorf("Test failed. Expected Kraken API error. Actual %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := k.GetBalances(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Test failed. Expected %s. Actual %v", context.Canceled, err)
+	}
+
+	k.APISecret = "wrong"
+	if _, err := k.GetBalances(context.Background()); err == nil || err.Error() != "Kraken API error: EAPI:Invalid signature" {
+		t.Errorf("Test failed. Expected invalid signature error. Actual %v", err)
+	}
+}
+
+func TestOrderFromKraken(t *testing.T) {
+	order := orderFromKraken("OQCLML-BW3P3-BUCMWZ", KrakenOrder{
+		Status: "closed",
+		Description: KrakenOrderDescription{
+			Pair:      "XBTUSD",
+			Type:      "sell",
+			OrderType: "limit",
+			Price:     "1000.0",
+		},
+		Volume:     1.5,
+		VolumeExec: 1.5,
+		Price:      1000.2,
+	})
+
+	if order.Symbol != "BTC/USD" || order.Side != exchange.SideSell || order.Price != 1000 {
+		t.Errorf("Test failed. Unexpected order: %+v", order)
+	}
+
+	if order.Status != exchange.OrderStatusFilled || order.AvgPrice != 1000.2 {
+		t.Errorf("Test failed. Unexpected status: %+v", order)
+	}
+}
+
+func TestAssetFromKraken(t *testing.T) {
+	for asset, expected := range map[string]string{"XXBT": "BTC", "XBT": "BTC", "ZUSD": "USD", "XETH": "ETH", "USDT": "USDT"} {
+		if actual := AssetFromKraken(asset); actual != expected {
+			t.Errorf("Test failed. Expected %s for %s. Actual %s", expected, asset, actual)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package kraken
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+type (
+	// KrakenResponse is the envelope of every Kraken answer, Result is
+	// decoded once Error is known to be empty
+	KrakenResponse struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+
+	// KrakenBookEntry is a [price, volume, timestamp] array of the depth
+	KrakenBookEntry struct {
+		Price     float64
+		Amount    float64
+		Timestamp float64
+	}
+
+	KrakenOrderBook struct {
+		Bids []KrakenBookEntry `json:"bids"`
+		Asks []KrakenBookEntry `json:"asks"`
+	}
+
+	KrakenAssetPair struct {
+		Altname string `json:"altname"`
+		Base    string `json:"base"`
+		Quote   string `json:"quote"`
+	}
+
+	KrakenOrderDescription struct {
+		Pair      string `json:"pair"`
+		Type      string `json:"type"`
+		OrderType string `json:"ordertype"`
+		Price     string `json:"price"`
+		Order     string `json:"order"`
+	}
+
+	KrakenAddOrderResponse struct {
+		Description    KrakenOrderDescription `json:"descr"`
+		TransactionIDs []string               `json:"txid"`
+	}
+
+	KrakenCancelOrderResponse struct {
+		Count   int  `json:"count"`
+		Pending bool `json:"pending"`
+	}
+
+	KrakenOpenOrdersResponse struct {
+		Open map[string]KrakenOrder `json:"open"`
+	}
+
+	KrakenOrder struct {
+		Status      string                 `json:"status"`
+		OpenTime    float64                `json:"opentm"`
+		Description KrakenOrderDescription `json:"descr"`
+		Volume      float64                `json:"vol,string"`
+		VolumeExec  float64                `json:"vol_exec,string"`
+		Price       float64                `json:"price,string"`
+	}
+)
+
+func (e *KrakenBookEntry) UnmarshalJSON(data []byte) error {
+	var entry []interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+
+	if len(entry) < 3 {
+		return errors.New("Kraken book entry: expected [price, volume, timestamp]")
+	}
+
+	var (
+		values [3]float64
+		err    error
+	)
+	for i := range values {
+		switch v := entry[i].(type) {
+		case string:
+			values[i], err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				return err
+			}
+		case float64:
+			values[i] = v
+		default:
+			return errors.New("Kraken book entry: unexpected value type")
+		}
+	}
+
+	e.Price, e.Amount, e.Timestamp = values[0], values[1], values[2]
+	return nil
+}
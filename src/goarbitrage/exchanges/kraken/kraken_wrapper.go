@@ -0,0 +1,155 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/mgutz/logxi/v1"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+)
+
+func (k *Kraken) UpdateDepth(ctx context.Context, pair exchange.CurrencyPair) (exchange.OrderBook, error) {
+	symbol := k.FormatSymbol(pair)
+	if k.Verbose {
+		log.Info(fmt.Sprintf("%s polling %s", k.GetName(), symbol))
+	}
+
+	book, err := k.GetOrderBook(ctx, symbol, KRAKEN_DEPTH_COUNT)
+	if err != nil {
+		return exchange.OrderBook{}, err
+	}
+
+	var t exchange.OrderBook
+	for _, i := range book.Bids {
+		t.Bids = append(t.Bids, exchange.ItemBook(i))
+	}
+	for _, i := range book.Asks {
+		t.Asks = append(t.Asks, exchange.ItemBook(i))
+	}
+
+	return t, nil
+}
+
+func (k *Kraken) SubmitExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, side exchange.OrderSide, orderType exchange.OrderType, amount, price float64) (exchange.Order, error) {
+	var nativeType string
+	switch orderType {
+	case exchange.OrderTypeLimit:
+		nativeType = "limit"
+	case exchange.OrderTypeMarket:
+		nativeType = "market"
+	default:
+		return exchange.Order{}, fmt.Errorf("%s: unsupported order type %s", k.Name, orderType)
+	}
+
+	response, err := k.NewOrder(ctx, k.FormatSymbol(pair), string(side), nativeType, amount, price)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	if len(response.TransactionIDs) == 0 {
+		return exchange.Order{}, fmt.Errorf("%s: order accepted without transaction id", k.Name)
+	}
+
+	return exchange.Order{
+		ID:     response.TransactionIDs[0],
+		Symbol: pair.String(),
+		Side:   side,
+		Type:   orderType,
+		Price:  price,
+		Amount: amount,
+		Status: exchange.OrderStatusOpen,
+	}, nil
+}
+
+func (k *Kraken) CancelExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	if _, err := k.CancelOrder(ctx, orderID); err != nil {
+		return exchange.Order{}, err
+	}
+
+	return k.GetExchangeOrderInfo(ctx, pair, orderID)
+}
+
+func (k *Kraken) GetExchangeOrderInfo(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	orders, err := k.QueryOrders(ctx, orderID)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	order, ok := orders[orderID]
+	if !ok {
+		return exchange.Order{}, fmt.Errorf("%s: unknown order %s", k.Name, orderID)
+	}
+
+	return orderFromKraken(orderID, order), nil
+}
+
+// GetOpenOrders returns the open orders of the account
+func (k *Kraken) GetOpenOrders(ctx context.Context) ([]exchange.Order, error) {
+	response, err := k.GetOpenOrdersByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []exchange.Order
+	for id, o := range response {
+		orders = append(orders, orderFromKraken(id, o))
+	}
+
+	return orders, nil
+}
+
+func (k *Kraken) GetBalances(ctx context.Context) (map[string]exchange.Balance, error) {
+	balances, err := k.GetAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return balancesFromKraken(balances), nil
+}
+
+// balancesFromKraken maps the legacy asset names, Kraken only reports the
+// total so funds held by open orders are counted as available
+func balancesFromKraken(balances map[string]float64) map[string]exchange.Balance {
+	result := map[string]exchange.Balance{}
+	for asset, amount := range balances {
+		currency := AssetFromKraken(asset)
+		result[currency] = exchange.Balance{
+			Currency:  currency,
+			Available: amount,
+			Total:     amount,
+		}
+	}
+
+	return result
+}
+
+func orderFromKraken(id string, o KrakenOrder) exchange.Order {
+	orderType := exchange.OrderTypeLimit
+	if o.Description.OrderType == "market" {
+		orderType = exchange.OrderTypeMarket
+	}
+
+	symbol := common.StringToUpper(o.Description.Pair)
+	if pair, err := pairFormat.Parse(o.Description.Pair); err == nil {
+		symbol = pair.String()
+	}
+
+	price, _ := strconv.ParseFloat(o.Description.Price, 64)
+	isLive := o.Status == "pending" || o.Status == "open"
+	isCancelled := o.Status == "canceled" || o.Status == "expired"
+
+	return exchange.Order{
+		ID:           id,
+		Symbol:       symbol,
+		Side:         exchange.OrderSide(o.Description.Type),
+		Type:         orderType,
+		Price:        price,
+		Amount:       o.Volume,
+		FilledAmount: o.VolumeExec,
+		AvgPrice:     o.Price,
+		Status:       exchange.OrderStatusFromState(isLive, isCancelled, o.Volume, o.VolumeExec),
+	}
+}
@@ -0,0 +1,101 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+type (
+	OrderSide   string
+	OrderType   string
+	OrderStatus string
+
+	// Order is the exchange independent representation of an order, adapters
+	// map their native responses onto it
+	Order struct {
+		ID           string
+		Symbol       string
+		Side         OrderSide
+		Type         OrderType
+		Price        float64
+		Amount       float64
+		FilledAmount float64
+		AvgPrice     float64
+		Status       OrderStatus
+	}
+
+	// OpenOrdersLister is implemented by exchanges able to list the orders
+	// still open on the account
+	OpenOrdersLister interface {
+		GetOpenOrders(ctx context.Context) ([]Order, error)
+	}
+)
+
+const (
+	SideBuy  OrderSide = "buy"
+	SideSell OrderSide = "sell"
+
+	OrderTypeLimit  OrderType = "limit"
+	OrderTypeMarket OrderType = "market"
+
+	OrderStatusUnknown         OrderStatus = "unknown"
+	OrderStatusOpen            OrderStatus = "open"
+	OrderStatusPartiallyFilled OrderStatus = "partially_filled"
+	OrderStatusFilled          OrderStatus = "filled"
+	OrderStatusCancelled       OrderStatus = "cancelled"
+)
+
+// OrderStatusFromState derives the normalized status from the live and
+// cancelled flags reported by exchanges together with the filled amount
+func OrderStatusFromState(isLive, isCancelled bool, amount, filled float64) OrderStatus {
+	switch {
+	case isCancelled:
+		return OrderStatusCancelled
+	case isLive && filled > 0:
+		return OrderStatusPartiallyFilled
+	case isLive:
+		return OrderStatusOpen
+	case amount > 0 && filled >= amount:
+		return OrderStatusFilled
+	}
+
+	return OrderStatusUnknown
+}
+
+// IsClosed reports whether the order can't be filled any further
+func (o Order) IsClosed() bool {
+	return o.Status == OrderStatusFilled || o.Status == OrderStatusCancelled
+}
+
+// CancelOpenOrders cancels every order still open on the exchange, all
+// orders are tried even when some of them fail
+func CancelOpenOrders(ctx context.Context, e IBotExchange) error {
+	lister, ok := e.(OpenOrdersLister)
+	if !ok {
+		return fmt.Errorf("%s: listing open orders isn't supported", e.GetName())
+	}
+
+	orders, err := lister.GetOpenOrders(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, o := range orders {
+		pair, err := ParseCurrencyPair(o.Symbol)
+		if err == nil {
+			_, err = e.CancelExchangeOrder(ctx, pair, o.ID)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: cancel order %s: %s", e.GetName(), o.ID, err))
+			continue
+		}
+
+		log.Printf("%s: order %s %s cancelled\n", e.GetName(), o.ID, o.Symbol)
+	}
+
+	return errors.Join(errs...)
+}
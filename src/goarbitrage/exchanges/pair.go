@@ -0,0 +1,113 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+
+	"goarbitrage/common"
+)
+
+const (
+	PAIR_DELIMITER = "/"
+)
+
+type (
+	// CurrencyPair is the exchange independent form of a symbol, it is
+	// written as BASE/QUOTE in the config, the logs and the recorded books
+	CurrencyPair struct {
+		Base  string
+		Quote string
+	}
+
+	// PairFormat describes how an exchange spells its symbols. Aliases map
+	// canonical currency codes to the exchange ones (BTC to XBT), Quotes
+	// list canonical quote currencies used to split symbols which have no
+	// delimiter, symbols ending in none of them can't be parsed.
+	PairFormat struct {
+		Delimiter string
+		Uppercase bool
+		Aliases   map[string]string
+		Quotes    []string
+	}
+)
+
+func NewCurrencyPair(base, quote string) CurrencyPair {
+	return CurrencyPair{
+		Base:  common.StringToUpper(base),
+		Quote: common.StringToUpper(quote),
+	}
+}
+
+// ParseCurrencyPair parses the canonical BASE/QUOTE form
+func ParseCurrencyPair(pair string) (CurrencyPair, error) {
+	parts := common.SplitStrings(pair, PAIR_DELIMITER)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return CurrencyPair{}, fmt.Errorf("invalid currency pair %q, expected BASE%sQUOTE", pair, PAIR_DELIMITER)
+	}
+
+	return NewCurrencyPair(parts[0], parts[1]), nil
+}
+
+func (p CurrencyPair) String() string {
+	return p.Base + PAIR_DELIMITER + p.Quote
+}
+
+func (p CurrencyPair) IsEmpty() bool {
+	return p.Base == "" && p.Quote == ""
+}
+
+// Format returns the native symbol of the pair
+func (f PairFormat) Format(pair CurrencyPair) string {
+	symbol := f.alias(pair.Base) + f.Delimiter + f.alias(pair.Quote)
+	if f.Uppercase {
+		return common.StringToUpper(symbol)
+	}
+
+	return common.StringToLower(symbol)
+}
+
+// Parse turns a native symbol into the canonical pair
+func (f PairFormat) Parse(symbol string) (CurrencyPair, error) {
+	upper := common.StringToUpper(symbol)
+
+	var base, quote string
+	switch {
+	case f.Delimiter != "":
+		parts := common.SplitStrings(upper, common.StringToUpper(f.Delimiter))
+		if len(parts) == 2 {
+			base, quote = parts[0], parts[1]
+		}
+	default:
+		// the longest quote wins so that BTCGUSD isn't read as BTCG/USD
+		for _, i := range f.Quotes {
+			native := common.StringToUpper(f.alias(i))
+			if len(upper) > len(native) && len(native) > len(quote) && strings.HasSuffix(upper, native) {
+				base, quote = upper[:len(upper)-len(native)], native
+			}
+		}
+	}
+
+	if base == "" || quote == "" {
+		return CurrencyPair{}, fmt.Errorf("unable to parse symbol %q", symbol)
+	}
+
+	return NewCurrencyPair(f.unalias(base), f.unalias(quote)), nil
+}
+
+func (f PairFormat) alias(currency string) string {
+	if native, ok := f.Aliases[currency]; ok {
+		return native
+	}
+
+	return currency
+}
+
+func (f PairFormat) unalias(native string) string {
+	for currency, i := range f.Aliases {
+		if common.StringToUpper(i) == native {
+			return currency
+		}
+	}
+
+	return native
+}
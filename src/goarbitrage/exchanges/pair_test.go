@@ -0,0 +1,55 @@
+package exchange
+
+import (
+	"testing"
+)
+
+func TestParseCurrencyPair(t *testing.T) {
+	pair, err := ParseCurrencyPair("btc/usd")
+	if err != nil {
+		t.Fatalf("Test failed. ParseCurrencyPair() error: %s", err)
+	}
+
+	if pair != NewCurrencyPair("BTC", "USD") || pair.String() != "BTC/USD" {
+		t.Errorf("Test failed. Expected BTC/USD. Actual %s", pair)
+	}
+
+	for _, i := range []string{"BTCUSD", "BTC/", "/USD", "BTC/USD/EUR"} {
+		if _, err := ParseCurrencyPair(i); err == nil {
+			t.Errorf("Test failed. ParseCurrencyPair(%q) should fail", i)
+		}
+	}
+}
+
+func TestPairFormat(t *testing.T) {
+	pair := NewCurrencyPair("BTC", "USD")
+	tests := []struct {
+		format PairFormat
+		symbol string
+	}{
+		{PairFormat{Quotes: []string{"USD"}}, "btcusd"},
+		{PairFormat{Delimiter: "-", Uppercase: true}, "BTC-USD"},
+		{PairFormat{Uppercase: true, Aliases: map[string]string{"BTC": "XXBT", "USD": "ZUSD"}, Quotes: []string{"USD"}}, "XXBTZUSD"},
+		{PairFormat{Uppercase: true, Quotes: []string{"USD"}}, "BTCUSD"},
+	}
+
+	for _, test := range tests {
+		if symbol := test.format.Format(pair); symbol != test.symbol {
+			t.Errorf("Test failed. Expected %s. Actual %s", test.symbol, symbol)
+		}
+
+		actual, err := test.format.Parse(test.symbol)
+		if err != nil || actual != pair {
+			t.Errorf("Test failed. Expected %s from %s. Actual %s, %v", pair, test.symbol, actual, err)
+		}
+	}
+
+	usd := PairFormat{Uppercase: true, Quotes: []string{"USD", "GUSD", "BTC"}}
+	if actual, err := usd.Parse("BTCGUSD"); err != nil || actual != NewCurrencyPair("BTC", "GUSD") {
+		t.Errorf("Test failed. Expected BTC/GUSD. Actual %s, %v", actual, err)
+	}
+
+	if actual, err := usd.Parse("ABCDEF"); err == nil {
+		t.Errorf("Test failed. Expected unknown quote to fail. Actual %s", actual)
+	}
+}
@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"context"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	ENDPOINT_PUBLIC  EndpointClass = "public"
+	ENDPOINT_PRIVATE EndpointClass = "private"
+)
+
+type (
+	// EndpointClass groups the endpoints of an exchange sharing a rate limit
+	EndpointClass string
+
+	// RateLimiter is a token bucket refilled with Rate tokens per second up
+	// to Burst. Requests beyond the bucket queue up by borrowing tokens, so
+	// the waits of concurrent callers add up instead of racing.
+	RateLimiter struct {
+		mu     sync.Mutex
+		name   string
+		rate   float64
+		burst  float64
+		tokens float64
+		last   time.Time
+	}
+)
+
+// NewRateLimiter returns a full bucket, nil when the rate is not positive
+// which leaves requests unlimited
+func NewRateLimiter(name string, rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		name:   name,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent, the delay is logged. The token
+// is handed back when the context ends first.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+
+	delay := r.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	log.Printf("%s requests rate limited, delayed by %s\n", r.name, delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		r.tokens++
+		r.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (r *RateLimiter) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-r.tokens / r.rate * float64(time.Second))
+}
@@ -0,0 +1,52 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"goarbitrage/config"
+)
+
+func TestRateLimiter(t *testing.T) {
+	r := NewRateLimiter("test", 100, 2)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := r.Wait(context.Background()); err != nil {
+			t.Fatalf("Test failed. Wait() error: %s", err)
+		}
+	}
+
+	// the burst passes right away, the other 3 wait 10ms each
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("Test failed. Expected about 30ms. Actual %s", elapsed)
+	}
+
+	r = NewRateLimiter("test", 1, 1)
+	r.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Test failed. Expected %s. Actual %v", context.DeadlineExceeded, err)
+	}
+
+	if delay := r.reserve(); delay > time.Second {
+		t.Errorf("Test failed. Expected cancelled wait to hand back its token. Actual delay %s", delay)
+	}
+}
+
+func TestSetRateLimits(t *testing.T) {
+	e := ExchangeBase{Name: "Test"}
+	if err := e.WaitRateLimit(context.Background(), ENDPOINT_PUBLIC); err != nil {
+		t.Errorf("Test failed. Expected unlimited requests. Actual %s", err)
+	}
+
+	e.SetRateLimits(config.RateLimit{Rate: 1, Burst: 5}, config.RateLimit{Rate: 2})
+	e.SetRateLimits(config.RateLimit{Rate: 3, Burst: 1}, config.RateLimit{})
+
+	if e.RateLimiters[ENDPOINT_PUBLIC].rate != 3 || e.RateLimiters[ENDPOINT_PRIVATE].rate != 2 {
+		t.Errorf("Test failed. Expected zero rate to keep the limit. Actual %+v", e.RateLimiters)
+	}
+}
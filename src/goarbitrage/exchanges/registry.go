@@ -0,0 +1,106 @@
+package exchange
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"goarbitrage/common"
+	"goarbitrage/config"
+)
+
+type (
+	ExchangeFactory func() IBotExchange
+)
+
+var (
+	factoriesMu sync.Mutex
+	factories   = map[string]ExchangeFactory{}
+)
+
+// Register makes an adapter available under the name used as key in the
+// exchanges section of the config, adapters call it from an init function
+func Register(name string, factory ExchangeFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if _, ok := factories[name]; ok {
+		panic("exchange: adapter registered twice: " + name)
+	}
+
+	factories[name] = factory
+}
+
+// New creates the adapter registered under the name with its defaults set
+func New(name string) (IBotExchange, error) {
+	factoriesMu.Lock()
+	factory, ok := factories[name]
+	factoriesMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown exchange %q, available: %v", name, Names())
+	}
+
+	e := factory()
+	e.SetDefaults()
+	if e.GetName() != name {
+		return nil, fmt.Errorf("exchange %q registered as %q", e.GetName(), name)
+	}
+
+	return e, nil
+}
+
+func Names() []string {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Build sets up the enabled exchanges of the config. Every entry needs a
+// registered adapter so that a typo doesn't silently drop a venue, adapters
+// without an entry stay disabled.
+func Build(entries map[string]config.Exchange) (map[string]IBotExchange, error) {
+	names := Names()
+	for name := range entries {
+		if !common.StringDataCompare(names, name) {
+			return nil, fmt.Errorf("unknown exchange %q in config, available: %v", name, names)
+		}
+	}
+
+	exchanges := map[string]IBotExchange{}
+	for _, name := range names {
+		entry, ok := entries[name]
+		if !ok {
+			continue
+		}
+
+		if entry.Name != "" && entry.Name != name {
+			return nil, fmt.Errorf("config entry %q is named %q", name, entry.Name)
+		}
+
+		if !entry.Enabled {
+			continue
+		}
+
+		e, err := New(name)
+		if err != nil {
+			return nil, err
+		}
+
+		e.Setup(entry)
+		exchanges[name] = e
+	}
+
+	if len(exchanges) == 0 {
+		return nil, fmt.Errorf("no exchange enabled, available: %v", Names())
+	}
+
+	return exchanges, nil
+}
@@ -0,0 +1,84 @@
+package exchange
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"goarbitrage/config"
+)
+
+type stubExchange struct {
+	ExchangeBase
+}
+
+func (s *stubExchange) SetDefaults() {
+	s.Name = "Stub"
+}
+
+func (s *stubExchange) Setup(exch config.Exchange) {
+	s.Enabled = exch.Enabled
+	s.SetEnabledPairs(exch.EnabledPairs)
+}
+
+func (s *stubExchange) UpdateDepth(ctx context.Context, pair CurrencyPair) (OrderBook, error) {
+	return OrderBook{}, nil
+}
+
+func (s *stubExchange) GetBalances(ctx context.Context) (map[string]Balance, error) {
+	return nil, nil
+}
+
+func (s *stubExchange) SubmitExchangeOrder(ctx context.Context, pair CurrencyPair, side OrderSide, orderType OrderType, amount, price float64) (Order, error) {
+	return Order{}, nil
+}
+
+func (s *stubExchange) CancelExchangeOrder(ctx context.Context, pair CurrencyPair, orderID string) (Order, error) {
+	return Order{}, nil
+}
+
+func (s *stubExchange) GetExchangeOrderInfo(ctx context.Context, pair CurrencyPair, orderID string) (Order, error) {
+	return Order{}, nil
+}
+
+func init() {
+	Register("Stub", func() IBotExchange {
+		return new(stubExchange)
+	})
+}
+
+func TestBuild(t *testing.T) {
+	exchanges, err := Build(map[string]config.Exchange{
+		"Stub": {Name: "Stub", Enabled: true, EnabledPairs: []string{"BTC/USD"}},
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Build() error: %s", err)
+	}
+
+	if len(exchanges) != 1 || exchanges["Stub"].GetEnabledPairs()[0].String() != "BTC/USD" {
+		t.Errorf("Test failed. Unexpected exchanges: %v", exchanges)
+	}
+
+	tests := []struct {
+		entries map[string]config.Exchange
+		err     string
+	}{
+		{map[string]config.Exchange{"Stub": {Enabled: true}, "Stubb": {}}, "unknown exchange \"Stubb\""},
+		{map[string]config.Exchange{}, "no exchange enabled"},
+		{map[string]config.Exchange{"Stub": {Name: "Other", Enabled: true}}, "is named \"Other\""},
+		{map[string]config.Exchange{"Stub": {Enabled: false}}, "no exchange enabled"},
+	}
+
+	for _, test := range tests {
+		_, err := Build(test.entries)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Test failed. Expected error %s. Actual %v", test.err, err)
+		}
+	}
+}
+
+func TestNewUnknown(t *testing.T) {
+	if _, err := New("Unknown"); err == nil {
+		t.Error("Test failed. Expected error for unknown exchange")
+	}
+}
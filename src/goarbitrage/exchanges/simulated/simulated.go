@@ -0,0 +1,216 @@
+package simulated
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"goarbitrage/common"
+	"goarbitrage/config"
+	"goarbitrage/exchanges"
+)
+
+const (
+	SIMULATED_NAME = "Simulated"
+)
+
+var (
+	// simulated exchanges speak the canonical BASE/QUOTE form
+	pairFormat = exchange.PairFormat{
+		Delimiter: exchange.PAIR_DELIMITER,
+		Uppercase: true,
+	}
+)
+
+// Simulated is an in-process exchange whose books, latency, errors and fills
+// are scripted through a Scenario or the Go API. It is meant for tests and
+// demos and never touches the network. It isn't part of the registry as every
+// instance carries its own name.
+type Simulated struct {
+	exchange.ExchangeBase
+
+	mu       sync.Mutex
+	steps    map[string][]Step
+	served   map[string]int
+	last     map[string]Step
+	balances map[string]float64
+	fill     Fill
+	orders   []exchange.Order
+}
+
+// New creates an enabled simulated exchange without books
+func New(name string) *Simulated {
+	s := &Simulated{
+		steps:    map[string][]Step{},
+		served:   map[string]int{},
+		last:     map[string]Step{},
+		balances: map[string]float64{},
+	}
+	s.Name = name
+	s.SetDefaults()
+
+	return s
+}
+
+// LoadScenario reads a scenario from a JSON file
+func LoadScenario(path string) (Scenario, error) {
+	scenario := Scenario{}
+
+	file, err := common.ReadFile(path)
+	if err != nil {
+		return scenario, err
+	}
+
+	err = common.JSONDecode(file, &scenario)
+	if err != nil {
+		return scenario, err
+	}
+
+	if len(scenario.Exchanges) == 0 {
+		return scenario, errors.New("scenario without exchanges: " + path)
+	}
+
+	return scenario, nil
+}
+
+// Build creates the exchanges of the scenario keyed by name
+func (sc Scenario) Build() map[string]exchange.IBotExchange {
+	exchanges := map[string]exchange.IBotExchange{}
+
+	for name, e := range sc.Exchanges {
+		s := New(name)
+		s.SetFees(e.TakerFee, e.MakerFee)
+		if e.LotStep > 0 {
+			s.LotStep = e.LotStep
+		}
+
+		for currency, amount := range e.Balances {
+			s.SetBalance(currency, amount)
+		}
+
+		pairs := make([]string, 0, len(e.Books))
+		for pair := range e.Books {
+			pairs = append(pairs, pair)
+		}
+		sort.Strings(pairs)
+
+		for _, pair := range pairs {
+			s.SetSteps(pair, e.Books[pair]...)
+		}
+
+		s.SetFill(e.Fill)
+		exchanges[name] = s
+	}
+
+	return exchanges
+}
+
+func (s *Simulated) SetDefaults() {
+	if s.Name == "" {
+		s.Name = SIMULATED_NAME
+	}
+	s.Enabled = true
+	s.Verbose = false
+	s.TakerFee = 0.2
+	s.MakerFee = 0.1
+	s.LotStep = 0.00000001
+	s.PairFormat = pairFormat
+}
+
+func (s *Simulated) Setup(exch config.Exchange) {
+	if !exch.Enabled {
+		s.SetEnabled(false)
+		return
+	}
+
+	s.Enabled = true
+	s.Verbose = exch.Verbose
+	s.SetEnabledPairs(exch.EnabledPairs)
+	s.SetFees(exch.TakerFee, exch.MakerFee)
+	s.RESTPollingDelay = exch.RESTPollingDelay.Duration
+	s.DepthTimeout = exch.DepthTimeout.Duration
+	if exch.LotStep > 0 {
+		s.LotStep = exch.LotStep
+	}
+}
+
+// SetSteps replaces the steps served for the canonical pair and enables it
+func (s *Simulated) SetSteps(pair string, steps ...Step) {
+	if p, err := exchange.ParseCurrencyPair(pair); err == nil {
+		pair = p.String()
+	}
+
+	s.mu.Lock()
+	s.steps[pair] = steps
+	s.served[pair] = 0
+	s.mu.Unlock()
+
+	if !common.StringDataCompare(s.EnabledPairs, pair) {
+		s.SetEnabledPairs(append(s.EnabledPairs, pair))
+	}
+}
+
+// SetBalance sets the available funds of the currency, an exchange with
+// balances reports authenticated API support so that they are polled
+func (s *Simulated) SetBalance(currency string, amount float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.balances[common.StringToUpper(currency)] = amount
+	s.AuthenticatedAPISupport = true
+}
+
+func (s *Simulated) SetFill(fill Fill) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fill = fill
+}
+
+// GetOrders returns every order submitted so far in submission order
+func (s *Simulated) GetOrders() []exchange.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]exchange.Order{}, s.orders...)
+}
+
+// GetOpenOrders returns the orders neither filled nor cancelled
+func (s *Simulated) GetOpenOrders(ctx context.Context) ([]exchange.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var orders []exchange.Order
+	for _, o := range s.orders {
+		if !o.IsClosed() {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders, nil
+}
+
+// nextStep returns the step to serve for the pair, ok is false when the
+// pair has no steps
+func (s *Simulated) nextStep(pair string) (Step, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	steps := s.steps[pair]
+	if len(steps) == 0 {
+		return Step{}, false
+	}
+
+	i := s.served[pair]
+	if i >= len(steps) {
+		i = len(steps) - 1
+	}
+	s.served[pair] = i + 1
+
+	if steps[i].Error == "" {
+		s.last[pair] = steps[i]
+	}
+
+	return steps[i], true
+}
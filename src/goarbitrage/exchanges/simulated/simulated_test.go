@@ -0,0 +1,157 @@
+package simulated
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"goarbitrage/exchanges"
+)
+
+const testScenario = `{
+	"exchanges": {
+		"Alpha": {
+			"taker_fee": 0.1,
+			"balances": {"BTC": 1, "USD": 1000},
+			"books": {
+				"btc/usd": [
+					{"bids": [{"price": 99, "amount": 1}], "asks": [{"price": 100, "amount": 1}]},
+					{"error": "maintenance"},
+					{"bids": [{"price": 101, "amount": 2}], "asks": [{"price": 102, "amount": 2}]}
+				]
+			}
+		}
+	}
+}`
+
+func TestScenario(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simulated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "scenario.json")
+	if err := ioutil.WriteFile(file, []byte(testScenario), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	scenario, err := LoadScenario(file)
+	if err != nil {
+		t.Fatalf("Test failed. LoadScenario() error: %s", err)
+	}
+
+	s := scenario.Build()["Alpha"].(*Simulated)
+	if s.GetName() != "Alpha" || s.GetTakerFee() != 0.1 || !s.IsAuthenticated() {
+		t.Fatalf("Test failed. Unexpected exchange: %+v", s.ExchangeBase)
+	}
+
+	pair := exchange.NewCurrencyPair("BTC", "USD")
+	if pairs := s.GetEnabledPairs(); len(pairs) != 1 || pairs[0] != pair {
+		t.Fatalf("Test failed. Expected BTC/USD enabled. Actual %v", pairs)
+	}
+
+	prices := []float64{100, 0, 102, 102}
+	for i, price := range prices {
+		book, err := s.UpdateDepth(context.Background(), pair)
+		if price == 0 {
+			if err == nil || !strings.Contains(err.Error(), "maintenance") {
+				t.Errorf("Test failed. Poll %d expected maintenance error. Actual %v", i, err)
+			}
+			continue
+		}
+
+		if err != nil || book.Asks[0].Price != price {
+			t.Errorf("Test failed. Poll %d unexpected book: %+v, %v", i, book, err)
+		}
+	}
+}
+
+func TestLatency(t *testing.T) {
+	s := New("Slow")
+	s.SetSteps("BTC/USD", Step{LatencyMs: 1000, Asks: []exchange.ItemBook{{Price: 100, Amount: 1}}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := s.UpdateDepth(ctx, exchange.NewCurrencyPair("BTC", "USD")); err != context.DeadlineExceeded {
+		t.Errorf("Test failed. Expected %s. Actual %v", context.DeadlineExceeded, err)
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Test failed. Expected poll to stop at the deadline. Actual %s", time.Since(start))
+	}
+}
+
+func TestOrders(t *testing.T) {
+	s := New("Alpha")
+	s.TakerFee, s.MakerFee = 0.5, 0.5
+	s.SetBalance("USD", 1000)
+	s.SetSteps("BTC/USD", Step{
+		Bids: []exchange.ItemBook{{Price: 99, Amount: 1}},
+		Asks: []exchange.ItemBook{{Price: 100, Amount: 1}},
+	})
+	pair := exchange.NewCurrencyPair("BTC", "USD")
+	s.UpdateDepth(context.Background(), pair)
+
+	order, err := s.SubmitExchangeOrder(context.Background(), pair, exchange.SideBuy, exchange.OrderTypeMarket, 2, 0)
+	if err != nil {
+		t.Fatalf("Test failed. SubmitExchangeOrder() error: %s", err)
+	}
+
+	if order.AvgPrice != 100 || order.Status != exchange.OrderStatusFilled {
+		t.Errorf("Test failed. Unexpected order: %+v", order)
+	}
+
+	balances, _ := s.GetBalances(context.Background())
+	if balances["BTC"].Available != 2 || balances["USD"].Available != 799 {
+		t.Errorf("Test failed. Unexpected balances: %+v", balances)
+	}
+
+	s.SetFill(Fill{Unfilled: 1})
+	order, err = s.SubmitExchangeOrder(context.Background(), pair, exchange.SideSell, exchange.OrderTypeLimit, 1, 120)
+	if err != nil || order.Status != exchange.OrderStatusOpen {
+		t.Fatalf("Test failed. Expected open order. Actual %+v, %v", order, err)
+	}
+
+	order, err = s.CancelExchangeOrder(context.Background(), pair, order.ID)
+	if err != nil || order.Status != exchange.OrderStatusCancelled {
+		t.Errorf("Test failed. Expected cancelled order. Actual %+v, %v", order, err)
+	}
+
+	s.SetFill(Fill{Error: "rejected"})
+	if _, err := s.SubmitExchangeOrder(context.Background(), pair, exchange.SideBuy, exchange.OrderTypeLimit, 1, 100); err == nil {
+		t.Error("Test failed. Expected rejected order")
+	}
+
+	if orders := s.GetOrders(); len(orders) != 2 {
+		t.Errorf("Test failed. Expected 2 orders. Actual %d", len(orders))
+	}
+}
+
+func TestCancelOpenOrders(t *testing.T) {
+	s := New("Alpha")
+	s.SetBalance("USD", 1000)
+	pair := exchange.NewCurrencyPair("BTC", "USD")
+
+	s.SubmitExchangeOrder(context.Background(), pair, exchange.SideBuy, exchange.OrderTypeLimit, 1, 100)
+	s.SetFill(Fill{Unfilled: 1})
+	s.SubmitExchangeOrder(context.Background(), pair, exchange.SideBuy, exchange.OrderTypeLimit, 1, 100)
+	s.SubmitExchangeOrder(context.Background(), pair, exchange.SideBuy, exchange.OrderTypeLimit, 1, 90)
+
+	if err := exchange.CancelOpenOrders(context.Background(), s); err != nil {
+		t.Fatalf("Test failed. CancelOpenOrders() error: %s", err)
+	}
+
+	expected := []exchange.OrderStatus{exchange.OrderStatusFilled, exchange.OrderStatusCancelled, exchange.OrderStatusCancelled}
+	for i, o := range s.GetOrders() {
+		if o.Status != expected[i] {
+			t.Errorf("Test failed. Order %s expected %s. Actual %s", o.ID, expected[i], o.Status)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package simulated
+
+import (
+	"goarbitrage/exchanges"
+)
+
+type (
+	// Scenario describes a set of simulated exchanges keyed by name
+	Scenario struct {
+		Exchanges map[string]ExchangeScenario `json:"exchanges"`
+	}
+
+	// ExchangeScenario holds the fees, funds, books and fill behaviour of a
+	// single simulated exchange. Books lists the steps of every canonical
+	// pair, the pairs are enabled in sorted order.
+	ExchangeScenario struct {
+		TakerFee *float64           `json:"taker_fee"`
+		MakerFee *float64           `json:"maker_fee"`
+		LotStep  float64            `json:"lot_step"`
+		Balances map[string]float64 `json:"balances"`
+		Books    map[string][]Step  `json:"books"`
+		Fill     Fill               `json:"fill"`
+	}
+
+	// Step is what a single poll of a pair returns. Every poll serves the
+	// next step, the last one is repeated once all were served. LatencyMs
+	// delays the response and Error fails the poll instead of sending a book.
+	Step struct {
+		Bids      []exchange.ItemBook `json:"bids"`
+		Asks      []exchange.ItemBook `json:"asks"`
+		LatencyMs int                 `json:"latency_ms"`
+		Error     string              `json:"error"`
+	}
+
+	// Fill decides how submitted orders execute. Unfilled is the part of the
+	// amount left open, zero fills orders completely and one leaves them
+	// untouched. Error rejects every order.
+	Fill struct {
+		Unfilled float64 `json:"unfilled"`
+		Error    string  `json:"error"`
+	}
+)
@@ -0,0 +1,167 @@
+package simulated
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+)
+
+// UpdateDepth serves the next step of the pair, its latency ends early when
+// the context does
+func (s *Simulated) UpdateDepth(ctx context.Context, pair exchange.CurrencyPair) (exchange.OrderBook, error) {
+	step, ok := s.nextStep(pair.String())
+	if !ok {
+		return exchange.OrderBook{}, fmt.Errorf("%s: no steps for %s", s.Name, pair)
+	}
+
+	if step.LatencyMs > 0 {
+		timer := time.NewTimer(time.Duration(step.LatencyMs) * time.Millisecond)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return exchange.OrderBook{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if step.Error != "" {
+		return exchange.OrderBook{}, fmt.Errorf("%s: %s", s.Name, step.Error)
+	}
+
+	return exchange.OrderBook{
+		Bids: append([]exchange.ItemBook{}, step.Bids...),
+		Asks: append([]exchange.ItemBook{}, step.Asks...),
+	}, nil
+}
+
+// SubmitExchangeOrder fills the order at its limit price, market orders at
+// the best price of the last served book. The filled part settles the
+// balances right away including the taker fee.
+func (s *Simulated) SubmitExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, side exchange.OrderSide, orderType exchange.OrderType, amount, price float64) (exchange.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fill.Error != "" {
+		return exchange.Order{}, fmt.Errorf("%s: %s", s.Name, s.fill.Error)
+	}
+
+	if amount <= 0 {
+		return exchange.Order{}, fmt.Errorf("%s: invalid amount %v", s.Name, amount)
+	}
+
+	switch orderType {
+	case exchange.OrderTypeLimit:
+		if price <= 0 {
+			return exchange.Order{}, fmt.Errorf("%s: limit order without price", s.Name)
+		}
+	case exchange.OrderTypeMarket:
+		book := s.last[pair.String()]
+		levels := book.Asks
+		if side == exchange.SideSell {
+			levels = book.Bids
+		}
+		if len(levels) == 0 {
+			return exchange.Order{}, fmt.Errorf("%s: no %s book to fill market order", s.Name, pair)
+		}
+		price = levels[0].Price
+	default:
+		return exchange.Order{}, fmt.Errorf("%s: unsupported order type %s", s.Name, orderType)
+	}
+
+	filled := amount * (1 - s.fill.Unfilled)
+	cost := filled * price
+	fee := common.CalculateFee(cost, s.TakerFee)
+
+	if len(s.balances) > 0 {
+		if side == exchange.SideBuy && s.balances[pair.Quote] < cost+fee {
+			return exchange.Order{}, fmt.Errorf("%s: insufficient %s funds", s.Name, pair.Quote)
+		}
+		if side == exchange.SideSell && s.balances[pair.Base] < filled {
+			return exchange.Order{}, fmt.Errorf("%s: insufficient %s funds", s.Name, pair.Base)
+		}
+	}
+
+	if side == exchange.SideBuy {
+		s.balances[pair.Base] += filled
+		s.balances[pair.Quote] -= cost + fee
+	} else {
+		s.balances[pair.Base] -= filled
+		s.balances[pair.Quote] += cost - fee
+	}
+
+	order := exchange.Order{
+		ID:           strconv.Itoa(len(s.orders) + 1),
+		Symbol:       pair.String(),
+		Side:         side,
+		Type:         orderType,
+		Price:        price,
+		Amount:       amount,
+		FilledAmount: filled,
+		Status:       exchange.OrderStatusFromState(filled < amount, false, amount, filled),
+	}
+	if filled > 0 {
+		order.AvgPrice = price
+	}
+
+	s.orders = append(s.orders, order)
+	return order, nil
+}
+
+func (s *Simulated) CancelExchangeOrder(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.orderIndex(orderID)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	if s.orders[i].IsClosed() {
+		return exchange.Order{}, fmt.Errorf("%s: order %s is %s", s.Name, orderID, s.orders[i].Status)
+	}
+
+	s.orders[i].Status = exchange.OrderStatusCancelled
+	return s.orders[i], nil
+}
+
+func (s *Simulated) GetExchangeOrderInfo(ctx context.Context, pair exchange.CurrencyPair, orderID string) (exchange.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.orderIndex(orderID)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	return s.orders[i], nil
+}
+
+func (s *Simulated) GetBalances(ctx context.Context) (map[string]exchange.Balance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := map[string]exchange.Balance{}
+	for currency, amount := range s.balances {
+		result[currency] = exchange.Balance{
+			Currency:  currency,
+			Available: amount,
+			Total:     amount,
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Simulated) orderIndex(orderID string) (int, error) {
+	id, err := strconv.Atoi(orderID)
+	if err != nil || id < 1 || id > len(s.orders) {
+		return 0, fmt.Errorf("%s: unknown order id %s", s.Name, orderID)
+	}
+
+	return id - 1, nil
+}
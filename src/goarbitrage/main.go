@@ -1,51 +1,176 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/mgutz/logxi/v1"
 
 	"goarbitrage/arbitrage"
-	"goarbitrage/common"
 	"goarbitrage/config"
 	"goarbitrage/exchanges"
-	"goarbitrage/exchanges/bitfinex"
-	"goarbitrage/exchanges/gemini"
+	_ "goarbitrage/exchanges/binance"
+	_ "goarbitrage/exchanges/bitfinex"
+	_ "goarbitrage/exchanges/bitstamp"
+	_ "goarbitrage/exchanges/coinbase"
+	_ "goarbitrage/exchanges/gemini"
+	_ "goarbitrage/exchanges/kraken"
+	"goarbitrage/paper"
+	"goarbitrage/recorder"
 	"goarbitrage/telegram"
 )
 
+const (
+	SHUTDOWN_TIMEOUT = 10 * time.Second
+	// SHUTDOWN_MARGIN is kept from the shutdown budget for the hooks running
+	// after a bounded one
+	SHUTDOWN_MARGIN = time.Second
+)
+
 type (
 	Bot struct {
 		config    *config.Config
 		arbitrer  *arbitrage.ArbitrageStrategy
 		exchanges map[string]exchange.IBotExchange
-		shutdown  chan bool
+		hooks     []shutdownHook
+
+		// recorder is closed before a forced exit, deadline is when the
+		// exit is forced and zero until a signal arrives
+		mu       sync.Mutex
+		recorder *recorder.Recorder
+		deadline time.Time
+	}
+
+	shutdownHook struct {
+		name string
+		run  func() error
 	}
 )
 
 var (
 	bot Bot
+
+	backtestDir = flag.String("backtest", "", "replay the order books recorded in the directory instead of watching the exchanges")
 )
 
-func HandleInterrupt() {
+// OnShutdown registers a cleanup hook, Shutdown runs the hooks in reverse
+// order of registration
+func (b *Bot) OnShutdown(name string, run func() error) {
+	b.hooks = append(b.hooks, shutdownHook{name: name, run: run})
+}
+
+// HandleInterrupt cancels the watch loop on SIGINT or SIGTERM. The exit is
+// forced by a second signal or when the shutdown doesn't complete within
+// the shutdown timeout, the recorder is closed first so that its current
+// file stays complete.
+func HandleInterrupt(cancel context.CancelFunc) {
 	s := make(chan os.Signal, 1)
 	signal.Notify(s, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		sig := <-s
 		log.Info("Captured signal", "info", sig.String())
-		Shutdown()
+		timeout := shutdownTimeout()
+		bot.setDeadline(time.Now().Add(timeout))
+		cancel()
+
+		select {
+		case sig = <-s:
+			log.Error("Captured second signal, forcing exit", "error", sig.String())
+		case <-time.After(timeout):
+			log.Error("Shutdown timed out, forcing exit", "error", timeout.String())
+		}
+
+		if err := bot.closeRecorder(); err != nil {
+			log.Error("Error close recorder", "error", err.Error())
+		}
+		os.Exit(1)
 	}()
 }
 
+func (b *Bot) setRecorder(rec *recorder.Recorder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.recorder = rec
+}
+
+func (b *Bot) closeRecorder() error {
+	b.mu.Lock()
+	rec := b.recorder
+	b.mu.Unlock()
+
+	if rec == nil {
+		return nil
+	}
+
+	return rec.Close()
+}
+
+func (b *Bot) setDeadline(deadline time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deadline = deadline
+}
+
+// shutdownContext bounds a cleanup hook by the time left before the exit is
+// forced, keeping a margin for the hooks running after it. Without a signal
+// the whole shutdown timeout is left.
+func (b *Bot) shutdownContext() (context.Context, context.CancelFunc) {
+	b.mu.Lock()
+	deadline := b.deadline
+	b.mu.Unlock()
+
+	if deadline.IsZero() {
+		deadline = time.Now().Add(shutdownTimeout())
+	}
+
+	margin := SHUTDOWN_MARGIN
+	if left := time.Until(deadline); left < 2*margin {
+		margin = left / 2
+	}
+
+	return context.WithDeadline(context.Background(), deadline.Add(-margin))
+}
+
+// shutdownTimeout bounds the cleanup after a signal
+func shutdownTimeout() time.Duration {
+	if config.Cfg.Settings.ShutdownTimeout.Duration > 0 {
+		return config.Cfg.Settings.ShutdownTimeout.Duration
+	}
+
+	return SHUTDOWN_TIMEOUT
+}
+
+// Shutdown runs the cleanup hooks and exits, failed hooks are logged and
+// don't stop the others
 func Shutdown() {
 	log.Info("Shutting down...", "info")
-	os.Exit(1)
+	for i := len(bot.hooks) - 1; i >= 0; i-- {
+		hook := bot.hooks[i]
+		if err := hook.run(); err != nil {
+			log.Error(fmt.Sprintf("Error %s", hook.name), "error", err.Error())
+		}
+	}
+
+	log.Info("Shutdown complete", "info")
+	os.Exit(0)
 }
 
 func main() {
-	HandleInterrupt()
+	flag.Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	HandleInterrupt(cancel)
 
 	// ---------------------------------------
 	log.Info("Load config file...")
@@ -53,42 +178,136 @@ func main() {
 
 	// ---------------------------------------
 	cfg := config.Cfg
-	if cfg.Telegram.Enable {
+	if *backtestDir != "" {
+		log.Info("Backtest mode, telegram disabled", "info")
+	} else if cfg.Telegram.Enable {
 		log.Info("Load telegram notify...")
 		if err := telegram.Init(); err != nil {
 			log.Fatal("Error load telegram notify", "fatal", err.Error())
 		}
+		bot.OnShutdown("send telegram message", func() error {
+			return telegram.SendTelegramMessage("goarbitrage stopped")
+		})
 	} else {
 		log.Info("Telegram disabled", "info")
 	}
 
 	// ---------------------------------------
 	log.Info("Init exchanges...")
-	bot.exchanges = map[string]exchange.IBotExchange{}
-	for _, i := range []exchange.IBotExchange{
-		new(bitfinex.Bitfinex),
-		new(gemini.Gemini),
-	} {
-		if i == nil {
-			continue
+	entries := cfg.Exchanges
+	if *backtestDir != "" {
+		// backtests never touch the network
+		entries = map[string]config.Exchange{}
+		for name, exch := range cfg.Exchanges {
+			exch.Websocket = false
+			entries[name] = exch
 		}
+	}
 
-		i.SetDefaults()
-		i.Setup(cfg.Exchanges[i.GetName()])
-		bot.exchanges[i.GetName()] = i
-		log.Info("Successfully set settings for exchange:", i.GetName())
+	exchanges, err := exchange.Build(entries)
+	if err != nil {
+		log.Fatal("Error init exchanges", "fatal", err.Error())
+	}
+	bot.exchanges = exchanges
+	for name := range bot.exchanges {
+		log.Info("Successfully set settings for exchange:", "info", name)
+	}
+	bot.OnShutdown("close exchanges", closeExchanges)
+	if cfg.Settings.CancelOrdersOnExit {
+		bot.OnShutdown("cancel open orders", cancelOpenOrders)
 	}
 
 	// ---------------------------------------
 	log.Info("Init arbitrage...")
-	bot.arbitrer = arbitrage.New()
+	arbitrer, err := arbitrage.New(cfg.Settings.Strategy)
+	if err != nil {
+		log.Fatal("Error init arbitrage", "fatal", err.Error())
+	}
+	bot.arbitrer = arbitrer
 	bot.arbitrer.Exchanges = bot.exchanges
+	if cfg.Paper.Enable {
+		log.Info("Paper trading enabled", "info")
+		bot.arbitrer.Paper = paper.New(cfg.Paper.Balances)
+		for source, conversion := range cfg.Settings.QuoteConversions {
+			bot.arbitrer.Paper.SetQuoteConversion(source, conversion.To, conversion.Rate)
+		}
+	}
+
+	if *backtestDir != "" {
+		runBacktest(*backtestDir)
+		return
+	}
+
+	if cfg.Recorder.Enable {
+		dir := cfg.Recorder.Dir
+		if !path.IsAbs(dir) {
+			dir = path.Join(config.RootDir, dir)
+		}
+
+		log.Info("Recording order books to", "info", dir)
+		rec, err := recorder.New(dir)
+		if err != nil {
+			log.Fatal("Error init recorder", "fatal", err.Error())
+		}
+		bot.arbitrer.Recorder = rec
+		bot.setRecorder(rec)
+		bot.OnShutdown("close recorder", bot.closeRecorder)
+	}
 
 	// ---------------------------------------
 	log.Info("Start watch loop...")
-	bot.arbitrer.Loop()
+	bot.arbitrer.Loop(ctx)
 
 	// ---------------------------------------
-	<-common.QuitChan
 	Shutdown()
 }
+
+// closeExchanges stops the background connections of the exchanges holding
+// any
+func closeExchanges() error {
+	for name, e := range bot.exchanges {
+		if c, ok := e.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				log.Error(fmt.Sprintf("Error close %s", name), "error", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// cancelOpenOrders cancels the open orders of the authenticated exchanges,
+// the watch loop is stopped already so the requests get their own deadline
+// within the time left before the exit is forced
+func cancelOpenOrders() error {
+	ctx, cancel := bot.shutdownContext()
+	defer cancel()
+
+	for name, e := range bot.exchanges {
+		if !e.IsAuthenticated() {
+			continue
+		}
+
+		log.Info("Cancel open orders of", "info", name)
+		if err := exchange.CancelOpenOrders(ctx, e); err != nil {
+			log.Error("Error cancel open orders", "error", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func runBacktest(dir string) {
+	log.Info("Load recorded order books from", "info", dir)
+	records, skipped, err := recorder.ReadDir(dir)
+	if err != nil {
+		log.Fatal("Error load recorded order books", "fatal", err.Error())
+	}
+
+	summary := bot.arbitrer.Backtest(records)
+	summary.SkippedFiles = skipped
+	fmt.Println(summary)
+	if bot.arbitrer.Paper != nil {
+		fmt.Println("paper account:", bot.arbitrer.Paper.Report())
+	}
+}
@@ -0,0 +1,275 @@
+package paper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"goarbitrage/common"
+	"goarbitrage/exchanges"
+)
+
+type (
+	// Trader simulates arbitrage trades against order books using virtual
+	// per exchange balances, the PnL is kept per quote currency
+	Trader struct {
+		mu          sync.Mutex
+		balances    map[string]map[string]float64
+		conversions map[string]conversion
+		pnl         map[string]float64
+		trades      int
+	}
+
+	// conversion settles legs of converted books in the source quote, one
+	// unit of it is worth rate units of to
+	conversion struct {
+		to   string
+		rate float64
+	}
+
+	// Order describes both legs of an arbitrage trade, Asks belong to the
+	// buy exchange and Bids to the sell exchange, fees are in percent
+	Order struct {
+		Base, Quote  string
+		Volume       float64
+		BuyExchange  string
+		SellExchange string
+		Asks         []exchange.ItemBook
+		Bids         []exchange.ItemBook
+		BuyFee       float64
+		SellFee      float64
+	}
+
+	Fill struct {
+		Exchange string
+		Amount   float64
+		AvgPrice float64
+		Total    float64
+		Fee      float64
+	}
+
+	// Trade is an executed order, Profit is in the quote currency
+	Trade struct {
+		Buy    Fill
+		Sell   Fill
+		Quote  string
+		Profit float64
+	}
+)
+
+// New creates a trader with the given starting inventory keyed by exchange
+// name and currency
+func New(balances map[string]map[string]float64) *Trader {
+	t := &Trader{
+		balances:    map[string]map[string]float64{},
+		conversions: map[string]conversion{},
+		pnl:         map[string]float64{},
+	}
+
+	for name, currencies := range balances {
+		t.balances[name] = map[string]float64{}
+		for currency, amount := range currencies {
+			t.balances[name][common.StringToUpper(currency)] = amount
+		}
+	}
+
+	return t
+}
+
+func (t *Trader) Balance(exchangeName, currency string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.balances[exchangeName][common.StringToUpper(currency)]
+}
+
+// SetQuoteConversion settles trades quoted in to on exchanges holding the
+// source quote instead, e.g. USD books of Binance against its USDT balance
+func (t *Trader) SetQuoteConversion(source, to string, rate float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.conversions[common.StringToUpper(source)] = conversion{to: common.StringToUpper(to), rate: rate}
+}
+
+// Funds returns the balance available for trades quoted in the currency,
+// converted from the source quote the exchange holds
+func (t *Trader) Funds(exchangeName, currency string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	held, rate := t.settlement(exchangeName, common.StringToUpper(currency))
+	return t.balances[exchangeName][held] * rate
+}
+
+// PnL returns the running profit keyed by quote currency, profits of pairs
+// quoted in different currencies aren't added up
+func (t *Trader) PnL() map[string]float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pnl := make(map[string]float64, len(t.pnl))
+	for quote, profit := range t.pnl {
+		pnl[quote] = profit
+	}
+
+	return pnl
+}
+
+func (t *Trader) Trades() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.trades
+}
+
+// Execute fills both legs of the order against the books and applies the
+// result to the virtual balances. The order is refused as a whole when the
+// books are too thin or the balances can't cover it.
+func (t *Trader) Execute(o Order) (Trade, error) {
+	if o.Volume <= 0 {
+		return Trade{}, errors.New("volume must be positive")
+	}
+
+	buy, err := fill(o.BuyExchange, o.Asks, o.Volume, o.BuyFee)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	sell, err := fill(o.SellExchange, o.Bids, o.Volume, o.SellFee)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	base, quote := common.StringToUpper(o.Base), common.StringToUpper(o.Quote)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	buyQuote, buyRate := t.settlement(o.BuyExchange, quote)
+	sellQuote, sellRate := t.settlement(o.SellExchange, quote)
+
+	if need, have := (buy.Total+buy.Fee)/buyRate, t.balances[o.BuyExchange][buyQuote]; have < need {
+		return Trade{}, fmt.Errorf("insufficient %s on %s: need %f, have %f", buyQuote, o.BuyExchange, need, have)
+	}
+
+	if have := t.balances[o.SellExchange][base]; have < sell.Amount {
+		return Trade{}, fmt.Errorf("insufficient %s on %s: need %f, have %f", base, o.SellExchange, sell.Amount, have)
+	}
+
+	t.adjust(o.BuyExchange, buyQuote, -(buy.Total+buy.Fee)/buyRate)
+	t.adjust(o.BuyExchange, base, buy.Amount)
+	t.adjust(o.SellExchange, base, -sell.Amount)
+	t.adjust(o.SellExchange, sellQuote, (sell.Total-sell.Fee)/sellRate)
+
+	trade := Trade{
+		Buy:    buy,
+		Sell:   sell,
+		Quote:  quote,
+		Profit: (sell.Total - sell.Fee) - (buy.Total + buy.Fee),
+	}
+
+	t.pnl[quote] += trade.Profit
+	t.trades++
+
+	return trade, nil
+}
+
+// Report returns a human readable summary of the running PnL per quote
+// currency and the balances
+func (t *Trader) Report() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	names := make([]string, 0, len(t.balances))
+	for name := range t.balances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	quotes := make([]string, 0, len(t.pnl))
+	for quote := range t.pnl {
+		quotes = append(quotes, quote)
+	}
+	sort.Strings(quotes)
+
+	parts := []string{fmt.Sprintf("trades: %d", t.trades)}
+	for _, quote := range quotes {
+		parts = append(parts, fmt.Sprintf("pnl %s: %f", quote, t.pnl[quote]))
+	}
+	for _, name := range names {
+		currencies := make([]string, 0, len(t.balances[name]))
+		for currency := range t.balances[name] {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+
+		for _, currency := range currencies {
+			parts = append(parts, fmt.Sprintf("%s %s: %f", name, currency, t.balances[name][currency]))
+		}
+	}
+
+	return common.JoinStrings(parts, ", ")
+}
+
+// settlement returns the currency the exchange settles trades quoted in
+// quote with and its rate to quote, the quote itself unless the exchange
+// only holds a source quote converted into it
+func (t *Trader) settlement(exchangeName, quote string) (string, float64) {
+	if _, ok := t.balances[exchangeName][quote]; ok {
+		return quote, 1
+	}
+
+	sources := make([]string, 0, len(t.conversions))
+	for source := range t.conversions {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		c := t.conversions[source]
+		if _, ok := t.balances[exchangeName][source]; ok && c.to == quote && c.rate > 0 {
+			return source, c.rate
+		}
+	}
+
+	return quote, 1
+}
+
+func (t *Trader) adjust(exchangeName, currency string, amount float64) {
+	if t.balances[exchangeName] == nil {
+		t.balances[exchangeName] = map[string]float64{}
+	}
+
+	t.balances[exchangeName][currency] += amount
+}
+
+// fill walks the price levels until the volume is filled
+func fill(exchangeName string, levels []exchange.ItemBook, volume, fee float64) (Fill, error) {
+	f := Fill{
+		Exchange: exchangeName,
+	}
+
+	for _, level := range levels {
+		amount := volume - f.Amount
+		if amount <= 0 {
+			break
+		}
+
+		if level.Amount < amount {
+			amount = level.Amount
+		}
+
+		f.Amount += amount
+		f.Total += amount * level.Price
+	}
+
+	if volume-f.Amount > 1e-9 {
+		return Fill{}, fmt.Errorf("not enough liquidity on %s: need %f, book has %f", exchangeName, volume, f.Amount)
+	}
+
+	f.AvgPrice = f.Total / f.Amount
+	f.Fee = common.CalculateFee(f.Total, fee)
+	return f, nil
+}
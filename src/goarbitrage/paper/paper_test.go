@@ -0,0 +1,133 @@
+package paper
+
+import (
+	"math"
+	"testing"
+
+	"goarbitrage/exchanges"
+)
+
+func testOrder(volume float64) Order {
+	return Order{
+		Base:         "BTC",
+		Quote:        "USD",
+		Volume:       volume,
+		BuyExchange:  "Cheap",
+		SellExchange: "Expensive",
+		Asks: []exchange.ItemBook{
+			{Price: 100, Amount: 1},
+			{Price: 101, Amount: 1},
+		},
+		Bids: []exchange.ItemBook{
+			{Price: 110, Amount: 2},
+		},
+		BuyFee:  0.1,
+		SellFee: 0.2,
+	}
+}
+
+func TestExecute(t *testing.T) {
+	trader := New(map[string]map[string]float64{
+		"Cheap":     {"usd": 1000},
+		"Expensive": {"BTC": 2},
+	})
+
+	trade, err := trader.Execute(testOrder(1.5))
+	if err != nil {
+		t.Fatalf("Test failed. Execute() error: %s", err)
+	}
+
+	if trade.Buy.Total != 150.5 || trade.Sell.Total != 165 {
+		t.Errorf("Test failed. Unexpected totals buy: %f, sell: %f", trade.Buy.Total, trade.Sell.Total)
+	}
+
+	expectedProfit := (165 - 0.33) - (150.5 + 0.1505)
+	if math.Abs(trade.Profit-expectedProfit) > 1e-9 {
+		t.Errorf("Test failed. Expected profit %f. Actual %f", expectedProfit, trade.Profit)
+	}
+
+	if math.Abs(trader.PnL()["USD"]-expectedProfit) > 1e-9 || trader.Trades() != 1 {
+		t.Errorf("Test failed. Unexpected pnl: %v, trades: %d", trader.PnL(), trader.Trades())
+	}
+
+	if trader.Balance("Cheap", "BTC") != 1.5 || trader.Balance("Expensive", "BTC") != 0.5 {
+		t.Errorf("Test failed. Unexpected BTC balances: %s", trader.Report())
+	}
+
+	if math.Abs(trader.Balance("Cheap", "USD")-(1000-150.6505)) > 1e-9 {
+		t.Errorf("Test failed. Unexpected USD balance on buy exchange: %s", trader.Report())
+	}
+}
+
+func TestExecuteRefused(t *testing.T) {
+	trader := New(map[string]map[string]float64{
+		"Cheap":     {"USD": 100},
+		"Expensive": {"BTC": 2},
+	})
+
+	if _, err := trader.Execute(testOrder(1.5)); err == nil {
+		t.Error("Test failed. Execute() should refuse trades the quote balance can't cover")
+	}
+
+	trader = New(map[string]map[string]float64{
+		"Cheap":     {"USD": 1000},
+		"Expensive": {"BTC": 1},
+	})
+
+	if _, err := trader.Execute(testOrder(1.5)); err == nil {
+		t.Error("Test failed. Execute() should refuse trades the base balance can't cover")
+	}
+
+	if _, err := trader.Execute(testOrder(3)); err == nil {
+		t.Error("Test failed. Execute() should refuse trades larger than the books")
+	}
+
+	if trader.Trades() != 0 || trader.Balance("Cheap", "USD") != 1000 {
+		t.Errorf("Test failed. Refused trades changed the balances: %s", trader.Report())
+	}
+}
+
+func TestExecuteConverted(t *testing.T) {
+	trader := New(map[string]map[string]float64{
+		"Cheap":     {"USDT": 400},
+		"Expensive": {"BTC": 2},
+	})
+	trader.SetQuoteConversion("USDT", "USD", 0.5)
+
+	if funds := trader.Funds("Cheap", "USD"); funds != 200 {
+		t.Errorf("Test failed. Expected 200 USD of funds. Actual %f", funds)
+	}
+
+	if _, err := trader.Execute(testOrder(1.5)); err != nil {
+		t.Fatalf("Test failed. Execute() error: %s", err)
+	}
+
+	if math.Abs(trader.Balance("Cheap", "USDT")-(400-150.6505/0.5)) > 1e-9 || trader.Balance("Cheap", "USD") != 0 {
+		t.Errorf("Test failed. Expected the buy to be paid in USDT: %s", trader.Report())
+	}
+}
+
+func TestPnLPerQuote(t *testing.T) {
+	trader := New(map[string]map[string]float64{
+		"Cheap":     {"USD": 1000, "BTC": 1},
+		"Expensive": {"BTC": 2, "ETH": 10},
+	})
+
+	order := testOrder(1)
+	if _, err := trader.Execute(order); err != nil {
+		t.Fatalf("Test failed. Execute() error: %s", err)
+	}
+
+	order.Base, order.Quote = "ETH", "BTC"
+	order.Asks = []exchange.ItemBook{{Price: 0.05, Amount: 10}}
+	order.Bids = []exchange.ItemBook{{Price: 0.06, Amount: 10}}
+	trade, err := trader.Execute(order)
+	if err != nil {
+		t.Fatalf("Test failed. Execute() error: %s", err)
+	}
+
+	pnl := trader.PnL()
+	if len(pnl) != 2 || pnl["BTC"] != trade.Profit || trade.Quote != "BTC" || pnl["USD"] <= 1 {
+		t.Errorf("Test failed. Expected the pnl of each quote on its own. Actual %v", pnl)
+	}
+}
@@ -0,0 +1,72 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mgutz/logxi/v1"
+)
+
+// ReadFile returns every record of a recorded file in file order. A file cut
+// short by a crash yields the records written before the cut.
+func ReadFile(name string) ([]Record, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var records []Record
+	dec := json.NewDecoder(bufio.NewReader(gz))
+	for {
+		var r Record
+		err := dec.Decode(&r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+}
+
+// ReadDir returns the records of every recorded file in dir sorted by
+// receive time. Files that can't be read are logged and skipped, their
+// number is returned along the records.
+func ReadDir(dir string) ([]Record, int, error) {
+	names, err := filepath.Glob(filepath.Join(dir, FILE_PREFIX+"*"+FILE_SUFFIX))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		records []Record
+		skipped int
+	)
+	for _, name := range names {
+		r, err := ReadFile(name)
+		if err != nil {
+			log.Warn("Skipped unreadable recording", "file", name, "warn", err.Error())
+			skipped++
+			continue
+		}
+		records = append(records, r...)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Received.Before(records[j].Received)
+	})
+	return records, skipped, nil
+}
@@ -0,0 +1,133 @@
+package recorder
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"goarbitrage/exchanges"
+)
+
+const (
+	FILE_PREFIX = "books-"
+	FILE_SUFFIX = ".jsonl.gz"
+	FILE_LAYOUT = "2006-01-02T15"
+)
+
+type (
+	// Record is a single order book as seen by the bot
+	Record struct {
+		Exchange string              `json:"exchange"`
+		Symbol   string              `json:"symbol"`
+		Received time.Time           `json:"received"`
+		Bids     []exchange.ItemBook `json:"bids"`
+		Asks     []exchange.ItemBook `json:"asks"`
+	}
+
+	// Recorder writes order books as gzip compressed JSON lines into one
+	// file per hour and process, a file is never appended to once closed
+	Recorder struct {
+		dir string
+
+		mu   sync.Mutex
+		hour time.Time
+		file *os.File
+		gz   *gzip.Writer
+		enc  *json.Encoder
+	}
+)
+
+func New(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Recorder{dir: dir}, nil
+}
+
+// FileName returns the name of the seq-th file holding the records received
+// in the hour of t
+func FileName(t time.Time, seq int) string {
+	return fmt.Sprintf("%s%s-%d%s", FILE_PREFIX, t.UTC().Format(FILE_LAYOUT), seq, FILE_SUFFIX)
+}
+
+// Record appends the order book to the file of the receive hour. Every
+// record is flushed, a crash leaves the file without its gzip trailer and
+// ReadFile still returns the records flushed before.
+func (r *Recorder) Record(resp exchange.TaskResponse, received time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hour := received.UTC().Truncate(time.Hour)
+	if r.file == nil || !hour.Equal(r.hour) {
+		if err := r.rotate(hour); err != nil {
+			return err
+		}
+	}
+
+	err := r.enc.Encode(Record{
+		Exchange: resp.Name,
+		Symbol:   resp.Symbol,
+		Received: received,
+		Bids:     resp.OrderBook.Bids,
+		Asks:     resp.OrderBook.Asks,
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.gz.Flush()
+}
+
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.close()
+}
+
+// rotate closes the current file and creates a new one for the hour, files
+// of the hour left by an earlier run or rotation are kept as they are
+func (r *Recorder) rotate(hour time.Time) error {
+	if err := r.close(); err != nil {
+		return err
+	}
+
+	var (
+		file *os.File
+		err  error
+	)
+	for seq := 0; ; seq++ {
+		file, err = os.OpenFile(path.Join(r.dir, FileName(hour, seq)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	r.hour = hour
+	r.file = file
+	r.gz = gzip.NewWriter(file)
+	r.enc = json.NewEncoder(r.gz)
+	return nil
+}
+
+func (r *Recorder) close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.gz.Close()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+
+	r.file, r.gz, r.enc = nil, nil, nil
+	return err
+}
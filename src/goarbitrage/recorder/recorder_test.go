@@ -0,0 +1,167 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"goarbitrage/exchanges"
+)
+
+func readRecords(t *testing.T, name string) []Record {
+	file, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("Test failed. Unable to open %s: %s", name, err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Test failed. Unable to read gzip %s: %s", name, err)
+	}
+
+	var records []Record
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("Test failed. Invalid JSON line: %s", err)
+		}
+		records = append(records, r)
+	}
+
+	return records
+}
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := New(dir)
+	if err != nil {
+		t.Fatalf("Test failed. New() error: %s", err)
+	}
+
+	resp := exchange.TaskResponse{
+		Name:   "Bitfinex",
+		Symbol: "BTCUSD",
+		OrderBook: exchange.OrderBook{
+			Bids: []exchange.ItemBook{{Price: 100, Amount: 1, Timestamp: 1500000000}},
+			Asks: []exchange.ItemBook{{Price: 101, Amount: 2}},
+		},
+	}
+
+	first := time.Date(2017, 5, 1, 10, 59, 0, 0, time.UTC)
+	second := first.Add(2 * time.Minute)
+	for _, i := range []time.Time{first, first.Add(time.Second), second} {
+		if err := r.Record(resp, i); err != nil {
+			t.Fatalf("Test failed. Record() error: %s", err)
+		}
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Test failed. Close() error: %s", err)
+	}
+
+	records := readRecords(t, path.Join(dir, "books-2017-05-01T10-0.jsonl.gz"))
+	if len(records) != 2 {
+		t.Fatalf("Test failed. Expected 2 records in the first hour. Actual %d", len(records))
+	}
+
+	if records[0].Exchange != "Bitfinex" || records[0].Symbol != "BTCUSD" || !records[0].Received.Equal(first) {
+		t.Errorf("Test failed. Unexpected record: %+v", records[0])
+	}
+
+	if len(records[0].Bids) != 1 || records[0].Bids[0] != resp.OrderBook.Bids[0] || records[0].Asks[0].Amount != 2 {
+		t.Errorf("Test failed. Unexpected levels: %+v", records[0])
+	}
+
+	if records := readRecords(t, path.Join(dir, "books-2017-05-01T11-0.jsonl.gz")); len(records) != 1 {
+		t.Errorf("Test failed. Expected 1 record in the second hour. Actual %d", len(records))
+	}
+
+	// a restart within the hour writes a file of its own
+	r, _ = New(dir)
+	if err := r.Record(resp, first.Add(time.Minute)); err != nil {
+		t.Fatalf("Test failed. Record() error: %s", err)
+	}
+	r.Close()
+
+	if records := readRecords(t, path.Join(dir, "books-2017-05-01T11-1.jsonl.gz")); len(records) != 1 {
+		t.Errorf("Test failed. Expected 1 record after the restart. Actual %d", len(records))
+	}
+
+	all, skipped, err := ReadDir(dir)
+	if err != nil || skipped != 0 {
+		t.Fatalf("Test failed. ReadDir() error: %v, %d skipped", err, skipped)
+	}
+
+	if len(all) != 4 {
+		t.Fatalf("Test failed. Expected 4 records. Actual %d", len(all))
+	}
+
+	for i := 1; i < len(all); i++ {
+		if all[i].Received.Before(all[i-1].Received) {
+			t.Errorf("Test failed. Records are not sorted by receive time: %v", all)
+		}
+	}
+}
+
+func TestRecorderCrash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	resp := exchange.TaskResponse{
+		Name:   "Bitfinex",
+		Symbol: "BTCUSD",
+		OrderBook: exchange.OrderBook{
+			Bids: []exchange.ItemBook{{Price: 100, Amount: 1}},
+			Asks: []exchange.ItemBook{{Price: 101, Amount: 2}},
+		},
+	}
+
+	// neither run is closed, both files lack the gzip trailer
+	first := time.Date(2017, 5, 1, 10, 0, 0, 0, time.UTC)
+	for run := 0; run < 2; run++ {
+		r, err := New(dir)
+		if err != nil {
+			t.Fatalf("Test failed. New() error: %s", err)
+		}
+
+		for i := 0; i < 3; i++ {
+			if err := r.Record(resp, first.Add(time.Duration(run*3+i)*time.Second)); err != nil {
+				t.Fatalf("Test failed. Record() error: %s", err)
+			}
+		}
+	}
+
+	records, err := ReadFile(path.Join(dir, FileName(first, 0)))
+	if err != nil || len(records) != 3 {
+		t.Fatalf("Test failed. Expected 3 records of the unclosed file. Actual %d, %v", len(records), err)
+	}
+
+	// a damaged file is skipped, the others are still replayed
+	if err := ioutil.WriteFile(path.Join(dir, FileName(first, 2)), []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	all, skipped, err := ReadDir(dir)
+	if err != nil || len(all) != 6 || skipped != 1 {
+		t.Fatalf("Test failed. Expected 6 records and 1 skipped file. Actual %d, %d, %v", len(all), skipped, err)
+	}
+
+	if !all[5].Received.Equal(first.Add(5*time.Second)) || all[5].Asks[0].Amount != 2 {
+		t.Errorf("Test failed. Unexpected last record: %+v", all[5])
+	}
+}
@@ -21,7 +21,7 @@ func Init() error {
 	c := config.Cfg
 	Bot, err = tgbotapi.NewBotAPI(c.Telegram.ApiKey)
 	if err != nil {
-		return fmt.Errorf("Init bot api", err.Error())
+		return fmt.Errorf("Init bot api: %s", err)
 	}
 
 	if Bot.Self.UserName == "" {
@@ -39,7 +39,7 @@ func SendTelegramMessage(message string) error {
 	msg := tgbotapi.NewMessage(c.Telegram.ChatId, message)
 	_, err := Bot.Send(msg)
 	if err != nil {
-		return fmt.Errorf("Error send message:", err.Error())
+		return fmt.Errorf("Error send message: %s", err)
 	}
 
 	return nil